@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/radenadri/go-boilerplate/config"
 	"github.com/radenadri/go-boilerplate/internal/delivery/http/routes"
+	"github.com/radenadri/go-boilerplate/internal/jobs"
 	"github.com/radenadri/go-boilerplate/pkg"
 )
 
@@ -19,9 +21,39 @@ func main() {
 	config.InitDB()
 	config.InitRedis()
 
+	// Init JWT signing and verification keys
+	if err := pkg.InitSigningKeys(); err != nil {
+		panic(err)
+	}
+
+	// Init pagination cursor signing
+	if err := pkg.InitCursorSigning(); err != nil {
+		panic(err)
+	}
+
+	// Init token revocation list
+	pkg.InitRevocationStore(config.RedisClient)
+
+	// Init failed login throttling
+	pkg.InitLoginThrottle(config.RedisClient)
+
+	// Init external identity providers and their login state
+	if err := pkg.InitOAuthProviders(); err != nil {
+		panic(err)
+	}
+	pkg.InitOAuthStateStore(config.RedisClient)
+
+	// Init mailer
+	if err := pkg.InitMailer(); err != nil {
+		panic(err)
+	}
+
 	// Init validator
 	pkg.InitValidator()
 
+	// Start background jobs
+	jobs.StartUserPurge(context.Background())
+
 	r := routes.InitRouter()
 	if err := r.Run(fmt.Sprintf(":%s", config.AppPort)); err != nil {
 		panic(err)
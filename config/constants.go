@@ -14,6 +14,9 @@ var (
 	AppApiVersion = GetEnvOrDefault("APP_API_VERSION", "v1")
 
 	CORSAllowOrigins = GetEnvOrDefault("CORS_ALLOWED_ORIGINS", "*")
+	TrustedProxies   = GetEnvOrDefault("TRUSTED_PROXIES", "")
+
+	ProblemTypeBaseURL = GetEnvOrDefault("PROBLEM_TYPE_BASE_URL", "")
 
 	DBHost       = GetEnvOrDefault("DB_HOST", "localhost")
 	DBPort       = GetEnvOrDefault("DB_PORT", "5432")
@@ -22,7 +25,52 @@ var (
 	DBPassword   = GetEnvOrDefault("DB_PASSWORD", "")
 	DBSSLEnabled = GetEnvOrDefault("DB_SSL_ENABLED", "false")
 
-	JWTSecret = GetEnvOrDefault("JWT_SECRET", "")
+	JWTSecret          = GetEnvOrDefault("JWT_SECRET", "")
+	JWTAccessTokenTTL  = GetEnvOrDefault("JWT_ACCESS_TOKEN_TTL", "15m")
+	JWTRefreshTokenTTL = GetEnvOrDefault("JWT_REFRESH_TOKEN_TTL", "720h")
+	JWTIssuer          = GetEnvOrDefault("JWT_ISSUER", "")
+	JWTAudience        = GetEnvOrDefault("JWT_AUDIENCE", "")
+	JWTLeeway          = GetEnvOrDefault("JWT_LEEWAY", "30s")
+	JWTAlgorithms      = GetEnvOrDefault("JWT_ALGORITHMS", "")
+
+	JWTSigningMethod    = GetEnvOrDefault("JWT_SIGNING_METHOD", "HS256")
+	JWTKeyID            = GetEnvOrDefault("JWT_KEY_ID", "")
+	JWTPrivateKeyPath   = GetEnvOrDefault("JWT_PRIVATE_KEY_PATH", "")
+	JWTVerificationKeys = GetEnvOrDefault("JWT_VERIFICATION_KEYS", "")
+
+	CursorSecret = GetEnvOrDefault("CURSOR_SECRET", "")
+
+	UserPurgeRetention = GetEnvOrDefault("USER_PURGE_RETENTION", "720h")
+	UserPurgeInterval  = GetEnvOrDefault("USER_PURGE_INTERVAL", "24h")
+
+	LoginMaxAttempts   = GetEnvOrDefault("LOGIN_MAX_ATTEMPTS", "5")
+	LoginIPMaxAttempts = GetEnvOrDefault("LOGIN_IP_MAX_ATTEMPTS", "20")
+	LoginFailureWindow = GetEnvOrDefault("LOGIN_FAILURE_WINDOW", "15m")
+	LoginLockoutBase   = GetEnvOrDefault("LOGIN_LOCKOUT_BASE", "1m")
+	LoginLockoutMax    = GetEnvOrDefault("LOGIN_LOCKOUT_MAX", "1h")
+
+	TOTPIssuer         = GetEnvOrDefault("TOTP_ISSUER", AppName)
+	TOTPSkew           = GetEnvOrDefault("TOTP_SKEW", "1")
+	MFAPendingTokenTTL = GetEnvOrDefault("MFA_PENDING_TOKEN_TTL", "5m")
+
+	OAuthProviders = GetEnvOrDefault("OAUTH_PROVIDERS", "")
+	OAuthStateTTL  = GetEnvOrDefault("OAUTH_STATE_TTL", "10m")
+
+	AuthRequireVerifiedEmail = GetEnvOrDefault("AUTH_REQUIRE_VERIFIED_EMAIL", "false")
+	EmailVerificationTTL     = GetEnvOrDefault("EMAIL_VERIFICATION_TTL", "24h")
+	EmailVerificationURL     = GetEnvOrDefault("EMAIL_VERIFICATION_URL", "http://localhost:3000/verify-email")
+
+	PasswordResetTTL = GetEnvOrDefault("PASSWORD_RESET_TTL", "1h")
+	PasswordResetURL = GetEnvOrDefault("PASSWORD_RESET_URL", "http://localhost:3000/reset-password")
+
+	MailDriver   = GetEnvOrDefault("MAIL_DRIVER", "")
+	MailFrom     = GetEnvOrDefault("MAIL_FROM", "no-reply@example.com")
+	MailFileDir  = GetEnvOrDefault("MAIL_FILE_DIR", "storage/mail")
+	SMTPHost     = GetEnvOrDefault("SMTP_HOST", "localhost")
+	SMTPPort     = GetEnvOrDefault("SMTP_PORT", "587")
+	SMTPUsername = GetEnvOrDefault("SMTP_USERNAME", "")
+	SMTPPassword = GetEnvOrDefault("SMTP_PASSWORD", "")
+	SMTPTimeout  = GetEnvOrDefault("SMTP_TIMEOUT", "10s")
 
 	RedisHost     = GetEnvOrDefault("REDIS_HOST", "localhost")
 	RedisPort     = GetEnvOrDefault("REDIS_PORT", "6379")
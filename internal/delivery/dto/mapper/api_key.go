@@ -0,0 +1,39 @@
+package mapper
+
+import (
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/utils"
+)
+
+func ToAPIKeyResponse(apiKey models.APIKey) *response.APIKeyResponse {
+	apiKeyResponse := &response.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		LastUsedIP: apiKey.LastUsedIP,
+		CreatedAt:  utils.ToTimestamp(apiKey.CreatedAt),
+	}
+
+	if apiKey.ExpiresAt != nil {
+		expiresAt := utils.ToTimestamp(*apiKey.ExpiresAt)
+		apiKeyResponse.ExpiresAt = &expiresAt
+	}
+
+	if apiKey.LastUsedAt != nil {
+		lastUsedAt := utils.ToTimestamp(*apiKey.LastUsedAt)
+		apiKeyResponse.LastUsedAt = &lastUsedAt
+	}
+
+	return apiKeyResponse
+}
+
+func ToAPIKeyResponses(apiKeys []models.APIKey) []response.APIKeyResponse {
+	apiKeyResponses := make([]response.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeyResponses = append(apiKeyResponses, *ToAPIKeyResponse(apiKey))
+	}
+
+	return apiKeyResponses
+}
@@ -0,0 +1,26 @@
+package mapper
+
+import (
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/utils"
+)
+
+// ToSessionResponses maps sessions for listing, flagging the one the caller
+// is using.
+func ToSessionResponses(sessions []models.Session, currentSessionID string) []response.SessionResponse {
+	sessionResponses := make([]response.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		sessionResponses = append(sessionResponses, response.SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			Current:    session.ID == currentSessionID,
+			LastSeenAt: utils.ToTimestamp(session.LastSeenAt),
+			CreatedAt:  utils.ToTimestamp(session.CreatedAt),
+		})
+	}
+
+	return sessionResponses
+}
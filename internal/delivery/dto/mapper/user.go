@@ -0,0 +1,78 @@
+package mapper
+
+import (
+	"strings"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/utils"
+)
+
+// UserFromRegisterRequest builds a new user from a registration payload. The
+// password must already be hashed; server-managed fields are left zero.
+func UserFromRegisterRequest(payload request.UserRegisterRequest, hashedPassword string) models.User {
+	return models.User{
+		Name:     payload.Name,
+		Username: strings.TrimSpace(payload.Username),
+		Email:    utils.NormalizeEmail(payload.Email),
+		Password: hashedPassword,
+	}
+}
+
+// ApplyUserUpdate copies the fields present in payload onto user and reports
+// whether the email address changed.
+func ApplyUserUpdate(user *models.User, payload request.UserUpdateRequest) bool {
+	emailChanged := false
+
+	if payload.Name != nil {
+		user.Name = *payload.Name
+	}
+
+	if payload.Username != nil {
+		user.Username = strings.TrimSpace(*payload.Username)
+	}
+
+	// A new address has to be verified again
+	if payload.Email != nil && utils.NormalizeEmail(*payload.Email) != user.Email {
+		user.Email = utils.NormalizeEmail(*payload.Email)
+		user.EmailVerifiedAt = nil
+		emailChanged = true
+	}
+
+	return emailChanged
+}
+
+func ToUserResponse(user models.User) *response.UserResponse {
+	userResponse := &response.UserResponse{
+		ID:               user.ID,
+		Name:             user.Name,
+		Username:         user.Username,
+		Email:            user.Email,
+		Roles:            user.RoleNames(),
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+		CreatedAt:        utils.ToTimestamp(user.CreatedAt),
+		UpdatedAt:        utils.ToTimestamp(user.UpdatedAt),
+	}
+
+	if user.EmailVerifiedAt != nil {
+		emailVerifiedAt := utils.ToTimestamp(*user.EmailVerifiedAt)
+		userResponse.EmailVerifiedAt = &emailVerifiedAt
+	}
+
+	if user.DeletedAt.Valid {
+		deletedAt := utils.ToTimestamp(user.DeletedAt.Time)
+		userResponse.DeletedAt = &deletedAt
+	}
+
+	return userResponse
+}
+
+func ToUserResponses(users []models.User) []response.UserResponse {
+	userResponses := make([]response.UserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, *ToUserResponse(user))
+	}
+
+	return userResponses
+}
@@ -1,6 +1,122 @@
 package request
 
+import (
+	"strings"
+	"time"
+)
+
+// UserLoginRequest identifies the user by Login, which is either a username
+// or an email address. Username is still accepted for older clients.
 type UserLoginRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=32"`
+	Login    string `json:"login" validate:"required_without=Username,max=255"`
+	Username string `json:"username" validate:"required_without=Login,max=255"`
 	Password string `json:"password" validate:"required,min=8,max=32"`
 }
+
+// Identifier returns the username or email the user is logging in with.
+func (r UserLoginRequest) Identifier() string {
+	if r.Login != "" {
+		return strings.TrimSpace(r.Login)
+	}
+
+	return strings.TrimSpace(r.Username)
+}
+
+type UserRegisterRequest struct {
+	Name                 string `json:"name" validate:"required,min=3"`
+	Username             string `json:"username" validate:"required,min=3,max=30,excludes=@"`
+	Email                string `json:"email" validate:"required,email"`
+	Password             string `json:"password" validate:"required,password,strong_password"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,eqfield=Password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token                string `json:"token" validate:"required"`
+	Password             string `json:"password" validate:"required,password,strong_password"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,eqfield=Password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword      string `json:"current_password" validate:"required"`
+	Password             string `json:"password" validate:"required,password,strong_password"`
+	PasswordConfirmation string `json:"password_confirmation" validate:"required,eqfield=Password"`
+}
+
+// TwoFactorCodeRequest carries a 6-digit TOTP code or, where accepted, a
+// recovery code.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+type TwoFactorLoginRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type UserUpdateRequest struct {
+	Name     *string `json:"name" validate:"omitempty,min=3"`
+	Username *string `json:"username" validate:"omitempty,min=3,max=30,excludes=@"`
+	Email    *string `json:"email" validate:"omitempty,email"`
+}
+
+type UserListQuery struct {
+	Sort           string `form:"sort"`
+	Search         string `form:"q" validate:"max=100"`
+	CreatedAfter   string `form:"created_after" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedBefore  string `form:"created_before" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	IncludeDeleted bool   `form:"include_deleted"`
+}
+
+// CursorQuery switches a list endpoint to keyset pagination. The total count
+// is only computed when WithTotal is set.
+type CursorQuery struct {
+	Cursor    string `form:"cursor"`
+	Limit     int    `form:"limit,default=10" validate:"gte=1,lte=100"`
+	WithTotal bool   `form:"with_total"`
+}
+
+// CreateAPIKeyRequest names a new key and lists the permissions it may use.
+// Keys without ExpiresAt never expire.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// UpdateAPIKeyRequest changes only the fields that are present.
+type UpdateAPIKeyRequest struct {
+	Name   *string  `json:"name" validate:"omitempty,min=1,max=100"`
+	Scopes []string `json:"scopes" validate:"omitempty,min=1,dive,required"`
+}
+
+// OAuthCallbackRequest carries the code and state the identity provider
+// appended to the redirect URL.
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" validate:"required,max=2048"`
+	State string `json:"state" validate:"required,max=512"`
+}
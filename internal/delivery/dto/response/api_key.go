@@ -0,0 +1,19 @@
+package response
+
+type APIKeyResponse struct {
+	ID         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	LastUsedIP string   `json:"last_used_ip"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+// APIKeyCreatedResponse is only returned once, when the key is created. The
+// full key cannot be retrieved afterwards.
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
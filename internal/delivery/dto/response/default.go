@@ -22,7 +22,9 @@ type Links struct {
 type Response struct {
 	Success bool              `json:"success"`
 	Data    interface{}       `json:"data,inline,omitempty"`
+	Message string            `json:"message,omitempty"`
 	Error   string            `json:"error,omitempty"`
+	Code    string            `json:"code,omitempty"`
 	Errors  []ValidationError `json:"errors,omitempty"`
 }
 
@@ -31,4 +33,5 @@ type PaginatedResponse[T any] struct {
 	Data       []T        `json:"data,inline,omitempty"`
 	Pagination Pagination `json:"pagination"`
 	Links      Links      `json:"links"`
+	NextCursor *string    `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,16 @@
+package response
+
+// Machine-readable values for Response.Code. Clients should branch on these
+// rather than on the human-readable Error message.
+const (
+	CodeAuthorizationMissing = "authorization_missing"
+	CodeAuthorizationScheme  = "authorization_scheme_invalid"
+	CodeTokenMalformed       = "token_malformed"
+	CodeTokenExpired         = "token_expired"
+	CodeTokenSignature       = "token_signature_invalid"
+	CodeTokenInvalid         = "token_invalid"
+	CodeTokenRevoked         = "token_revoked"
+	CodeSessionRevoked       = "session_revoked"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+)
@@ -0,0 +1,14 @@
+package response
+
+// Problem is an RFC 9457 "problem details" body, served as
+// application/problem+json to clients that ask for it. Code and Errors are
+// extension members carrying the same values as Response.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code,omitempty"`
+	Errors   []ValidationError `json:"errors,omitempty"`
+}
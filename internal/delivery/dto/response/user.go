@@ -1,14 +1,40 @@
 package response
 
+// UserLoginResponse carries either the token pair or, for accounts with
+// two-factor authentication, an MFA token to exchange at /login/2fa.
 type UserLoginResponse struct {
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
 
 type UserResponse struct {
-	ID        uint   `json:"id"`
-	Name      string `json:"name"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	CreatedAt string `json:"created_at"`
+	ID               uint     `json:"id"`
+	Name             string   `json:"name"`
+	Username         string   `json:"username"`
+	Email            string   `json:"email"`
+	Roles            []string `json:"roles,omitempty"`
+	EmailVerifiedAt  *string  `json:"email_verified_at"`
+	TwoFactorEnabled bool     `json:"two_factor_enabled"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+	DeletedAt        *string  `json:"deleted_at,omitempty"`
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// OAuthAuthorizationResponse points the client at the identity provider. The
+// client should keep State and check it matches the one the provider sends
+// back before calling the callback endpoint.
+type OAuthAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
 }
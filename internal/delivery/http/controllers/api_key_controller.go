@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/delivery/http/middlewares"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/internal/services"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+type APIKeyController struct {
+	APIKeyService *services.APIKeyService
+}
+
+func NewAPIKeyController() APIKeyController {
+	apiKeyRepository := repositories.NewAPIKeyRepository(config.DB)
+	userRepository := repositories.NewUserRepository(config.DB)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepository, userRepository)
+
+	return APIKeyController{
+		APIKeyService: apiKeyService,
+	}
+}
+
+// GetAPIKeys godoc
+// @Summary List my API keys
+// @Description List the authenticated user's API keys. Only key prefixes are returned
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]response.APIKeyResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/me/api-keys [get]
+func (controller *APIKeyController) GetAPIKeys(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	apiKeys, err := controller.APIKeyService.GetAPIKeys(identity)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    apiKeys,
+	})
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create an API key limited to the given scopes, which must be permissions the caller holds. The key is only returned in this response
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param payload body request.CreateAPIKeyRequest true "Key name, scopes and optional expiry"
+// @Security BearerAuth
+// @Success 201 {object} response.Response{data=response.APIKeyCreatedResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/me/api-keys [post]
+func (controller *APIKeyController) CreateAPIKey(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var createPayload request.CreateAPIKeyRequest
+
+	if !bindJSON(c, &createPayload) {
+		return
+	}
+
+	apiKeyResponse, err := controller.APIKeyService.CreateAPIKey(identity, createPayload)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response.Response{
+		Success: true,
+		Data:    apiKeyResponse,
+	})
+}
+
+// GetAPIKey godoc
+// @Summary Get one of my API keys
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=response.APIKeyResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/me/api-keys/{id} [get]
+func (controller *APIKeyController) GetAPIKey(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, ok := parseAPIKeyID(c)
+	if !ok {
+		return
+	}
+
+	apiKeyResponse, err := controller.APIKeyService.GetAPIKey(identity, id)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    apiKeyResponse,
+	})
+}
+
+// UpdateAPIKey godoc
+// @Summary Update one of my API keys
+// @Description Rename a key or replace its scopes. Only the supplied fields are changed
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Param payload body request.UpdateAPIKeyRequest true "Fields to update"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=response.APIKeyResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/me/api-keys/{id} [patch]
+func (controller *APIKeyController) UpdateAPIKey(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, ok := parseAPIKeyID(c)
+	if !ok {
+		return
+	}
+
+	var updatePayload request.UpdateAPIKeyRequest
+
+	if !bindJSON(c, &updatePayload) {
+		return
+	}
+
+	apiKeyResponse, err := controller.APIKeyService.UpdateAPIKey(identity, id, updatePayload)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    apiKeyResponse,
+	})
+}
+
+// DeleteAPIKey godoc
+// @Summary Delete one of my API keys
+// @Description Revoke an API key. Requests using it are rejected immediately
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/me/api-keys/{id} [delete]
+func (controller *APIKeyController) DeleteAPIKey(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	id, ok := parseAPIKeyID(c)
+	if !ok {
+		return
+	}
+
+	if err := controller.APIKeyService.DeleteAPIKey(identity, id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Message: "API key deleted",
+	})
+}
+
+func parseAPIKeyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		_ = c.Error(pkg.NewValidationError("invalid_api_key_id", "Invalid API key ID"))
+		return 0, false
+	}
+
+	return uint(id), true
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/internal/services"
+)
+
+type AuthController struct {
+	AuthService *services.AuthService
+}
+
+func NewAuthController() AuthController {
+	userRepository := repositories.NewUserRepository(config.DB)
+	authService := services.NewAuthService(userRepository, newSessionService())
+
+	return AuthController{
+		AuthService: authService,
+	}
+}
+
+// Login godoc
+// @Summary Login user
+// @Description Authenticate a user and return a JWT token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body request.UserLoginRequest true "Login credentials"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/login [post]
+func (controller *AuthController) Login(c *gin.Context) {
+	var userLoginPayload request.UserLoginRequest
+
+	if !bindJSON(c, &userLoginPayload) {
+		return
+	}
+
+	userResponse, err := controller.AuthService.Login(c.Request.Context(), userLoginPayload, clientInfo(c))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    userResponse,
+	})
+}
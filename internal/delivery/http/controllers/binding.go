@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+// bindJSON decodes and validates the request body into payload. On failure
+// the error is recorded on the context for middlewares.ErrorHandler and false
+// is returned.
+func bindJSON(c *gin.Context, payload interface{}) bool {
+	if err := c.ShouldBindJSON(payload); err != nil {
+		_ = c.Error(pkg.NewValidationError("invalid_request_body", "Invalid request body").Wrap(err))
+		return false
+	}
+
+	return validate(c, payload)
+}
+
+// bindQuery is the query string counterpart of bindJSON.
+func bindQuery(c *gin.Context, payload interface{}) bool {
+	if err := c.ShouldBindQuery(payload); err != nil {
+		_ = c.Error(pkg.NewValidationError("invalid_query", "Invalid query parameters").Wrap(err))
+		return false
+	}
+
+	return validate(c, payload)
+}
+
+func validate(c *gin.Context, payload interface{}) bool {
+	if err := pkg.ValidateStruct(payload); err != nil {
+		_ = c.Error(err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/internal/services"
+)
+
+type EmailVerificationController struct {
+	EmailVerificationService *services.EmailVerificationService
+}
+
+func NewEmailVerificationController() EmailVerificationController {
+	userRepository := repositories.NewUserRepository(config.DB)
+	emailVerificationService := services.NewEmailVerificationService(userRepository)
+
+	return EmailVerificationController{
+		EmailVerificationService: emailVerificationService,
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Confirm ownership of an email address with the token sent by email. Each token can be used once.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body request.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} response.Response{data=response.UserResponse}
+// @Failure 400 {object} response.Response
+// @Router /api/v1/verify-email [post]
+func (controller *EmailVerificationController) VerifyEmail(c *gin.Context) {
+	var verifyEmailPayload request.VerifyEmailRequest
+
+	if !bindJSON(c, &verifyEmailPayload) {
+		return
+	}
+
+	userResponse, err := controller.EmailVerificationService.VerifyEmail(c.Request.Context(), verifyEmailPayload)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    userResponse,
+	})
+}
+
+// ResendVerificationEmail godoc
+// @Summary Resend verification email
+// @Description Send a new verification link. The response is the same whether or not the address belongs to an unverified account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body request.ResendVerificationEmailRequest true "Email address"
+// @Success 202 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/verify-email/resend [post]
+func (controller *EmailVerificationController) ResendVerificationEmail(c *gin.Context) {
+	var resendPayload request.ResendVerificationEmailRequest
+
+	if !bindJSON(c, &resendPayload) {
+		return
+	}
+
+	if err := controller.EmailVerificationService.ResendVerificationEmail(c.Request.Context(), resendPayload); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{
+		Success: true,
+		Message: "If the address belongs to an unverified account, a verification email has been sent",
+	})
+}
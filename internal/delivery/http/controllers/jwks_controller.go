@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+type JWKSController struct{}
+
+func NewJWKSController() JWKSController {
+	return JWKSController{}
+}
+
+// GetJWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys that verify tokens issued by this service
+// @Tags auth
+// @Produce json
+// @Success 200 {object} pkg.JWKSet
+// @Failure 503 {object} response.Response
+// @Router /.well-known/jwks.json [get]
+func (controller *JWKSController) GetJWKS(c *gin.Context) {
+	if pkg.Keys == nil {
+		_ = c.Error(pkg.NewUnavailableError("signing_keys_unavailable", "JWT signing keys not configured"))
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, pkg.Keys.JWKS())
+}
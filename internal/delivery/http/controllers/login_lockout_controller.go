@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+)
+
+// GetLoginLockouts godoc
+// @Summary List login lockouts
+// @Description List usernames and client IPs that are currently locked out after repeated failed logins
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]pkg.LoginLockout}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/admin/lockouts [get]
+func (controller *AuthController) GetLoginLockouts(c *gin.Context) {
+	lockouts, err := controller.AuthService.GetLoginLockouts(c.Request.Context())
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    lockouts,
+	})
+}
+
+// ClearLoginLockout godoc
+// @Summary Clear a login lockout
+// @Description Lift the lockout of an account or client IP and forget its failed attempts. Usernames and emails are resolved to their account.
+// @Tags admin
+// @Produce json
+// @Param scope path string true "Lockout scope" Enums(username, ip)
+// @Param subject path string true "Username, email, user:<id> or IP address"
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Router /api/v1/admin/lockouts/{scope}/{subject} [delete]
+func (controller *AuthController) ClearLoginLockout(c *gin.Context) {
+	if err := controller.AuthService.ClearLoginLockout(c.Request.Context(), c.Param("scope"), c.Param("subject")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+	})
+}
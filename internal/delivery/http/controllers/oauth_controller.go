@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/internal/services"
+)
+
+type OAuthController struct {
+	OAuthService *services.OAuthService
+}
+
+func NewOAuthController() OAuthController {
+	userRepository := repositories.NewUserRepository(config.DB)
+	roleRepository := repositories.NewRoleRepository(config.DB)
+	linkedAccountRepository := repositories.NewLinkedAccountRepository(config.DB)
+	oauthService := services.NewOAuthService(userRepository, roleRepository, linkedAccountRepository, newSessionService())
+
+	return OAuthController{
+		OAuthService: oauthService,
+	}
+}
+
+// StartOAuthLogin godoc
+// @Summary Start an external login
+// @Description Create the authorization URL for an OpenID Connect provider. Send the user there and keep the returned state to compare with the one in the redirect
+// @Tags auth
+// @Produce json
+// @Param provider path string true "Provider name from OAUTH_PROVIDERS"
+// @Success 200 {object} response.Response{data=response.OAuthAuthorizationResponse}
+// @Failure 404 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/auth/{provider}/authorize [get]
+func (controller *OAuthController) StartOAuthLogin(c *gin.Context) {
+	authorizationResponse, err := controller.OAuthService.StartOAuthLogin(c.Request.Context(), c.Param("provider"))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    authorizationResponse,
+	})
+}
+
+// CompleteOAuthLogin godoc
+// @Summary Complete an external login
+// @Description Exchange the code and state from the provider redirect for a token pair, or an MFA token when two-factor authentication is enabled. New users are created and existing users with the same verified email are linked
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name from OAUTH_PROVIDERS"
+// @Param payload body request.OAuthCallbackRequest true "Code and state from the redirect"
+// @Success 200 {object} response.Response{data=response.UserLoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/auth/{provider}/callback [post]
+func (controller *OAuthController) CompleteOAuthLogin(c *gin.Context) {
+	var callbackPayload request.OAuthCallbackRequest
+
+	if !bindJSON(c, &callbackPayload) {
+		return
+	}
+
+	tokenResponse, err := controller.OAuthService.CompleteOAuthLogin(c.Request.Context(), c.Param("provider"), callbackPayload, clientInfo(c))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    tokenResponse,
+	})
+}
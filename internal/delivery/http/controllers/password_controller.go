@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/delivery/http/middlewares"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/internal/services"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+type PasswordController struct {
+	PasswordService *services.PasswordService
+}
+
+func NewPasswordController() PasswordController {
+	userRepository := repositories.NewUserRepository(config.DB)
+	passwordResetTokenRepository := repositories.NewPasswordResetTokenRepository(config.DB)
+	passwordService := services.NewPasswordService(userRepository, passwordResetTokenRepository, newSessionService())
+
+	return PasswordController{
+		PasswordService: passwordService,
+	}
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the address belongs to an account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body request.ForgotPasswordRequest true "Email address"
+// @Success 202 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/password/forgot [post]
+func (controller *PasswordController) ForgotPassword(c *gin.Context) {
+	var forgotPasswordPayload request.ForgotPasswordRequest
+
+	if !bindJSON(c, &forgotPasswordPayload) {
+		return
+	}
+
+	if err := controller.PasswordService.ForgotPassword(c.Request.Context(), forgotPasswordPayload); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.Response{
+		Success: true,
+		Message: "If the address belongs to an account, a password reset email has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password with a reset token. Every existing session of the user is signed out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body request.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Router /api/v1/password/reset [post]
+func (controller *PasswordController) ResetPassword(c *gin.Context) {
+	var resetPasswordPayload request.ResetPasswordRequest
+
+	if !bindJSON(c, &resetPasswordPayload) {
+		return
+	}
+
+	if err := controller.PasswordService.ResetPassword(c.Request.Context(), resetPasswordPayload); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Message: "Password has been reset",
+	})
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Change the password of the authenticated user. Every other session is signed out and a new token pair is returned.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param payload body request.ChangePasswordRequest true "Current and new password"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=response.UserLoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/me/password [post]
+func (controller *PasswordController) ChangePassword(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var changePasswordPayload request.ChangePasswordRequest
+
+	if !bindJSON(c, &changePasswordPayload) {
+		return
+	}
+
+	tokenResponse, err := controller.PasswordService.ChangePassword(c.Request.Context(), identity, changePasswordPayload, clientInfo(c))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    tokenResponse,
+	})
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/delivery/http/middlewares"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/internal/services"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+type SessionController struct {
+	SessionService *services.SessionService
+}
+
+func NewSessionController() SessionController {
+	return SessionController{
+		SessionService: newSessionService(),
+	}
+}
+
+// newSessionService builds the session service the other feature services
+// start and end sessions through.
+func newSessionService() *services.SessionService {
+	return services.NewSessionService(
+		repositories.NewUserRepository(config.DB),
+		repositories.NewRefreshTokenRepository(config.DB),
+		repositories.NewSessionRepository(config.DB),
+	)
+}
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access and refresh token pair. Refresh tokens are single use; replaying one revokes every token issued from the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body request.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/refresh [post]
+func (controller *SessionController) Refresh(c *gin.Context) {
+	var refreshTokenPayload request.RefreshTokenRequest
+
+	if !bindJSON(c, &refreshTokenPayload) {
+		return
+	}
+
+	tokenResponse, err := controller.SessionService.Refresh(refreshTokenPayload, clientInfo(c))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    tokenResponse,
+	})
+}
+
+// Logout godoc
+// @Summary Logout user
+// @Description Revoke the current access token. When a refresh token is supplied, every token issued from the same login is revoked as well.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body request.LogoutRequest false "Refresh token to revoke"
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /api/v1/logout [post]
+func (controller *SessionController) Logout(c *gin.Context) {
+	var logoutPayload request.LogoutRequest
+
+	if err := c.ShouldBindJSON(&logoutPayload); err != nil && !errors.Is(err, io.EOF) {
+		_ = c.Error(pkg.NewValidationError("invalid_request_body", "Invalid request body").Wrap(err))
+		return
+	}
+
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	if err := controller.SessionService.Logout(identity, logoutPayload); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+	})
+}
+
+// GetSessions godoc
+// @Summary List my sessions
+// @Description List the devices the authenticated user is signed in on. The session making the request is flagged as current
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]response.SessionResponse}
+// @Failure 401 {object} response.Response
+// @Router /api/v1/me/sessions [get]
+func (controller *SessionController) GetSessions(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	sessions, err := controller.SessionService.GetSessions(identity)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    sessions,
+	})
+}
+
+// RevokeSession godoc
+// @Summary Revoke one of my sessions
+// @Description Sign out a session of the authenticated user. Its refresh token stops working immediately and its access tokens are rejected
+// @Tags me
+// @Produce json
+// @Param id path string true "Session ID"
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/me/sessions/{id} [delete]
+func (controller *SessionController) RevokeSession(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	if err := controller.SessionService.RevokeSession(c.Request.Context(), identity, c.Param("id")); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Message: "Session revoked",
+	})
+}
+
+// clientInfo describes the client making the request, for recording sessions.
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
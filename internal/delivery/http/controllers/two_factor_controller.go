@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/delivery/http/middlewares"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/internal/services"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+type TwoFactorController struct {
+	TwoFactorService *services.TwoFactorService
+}
+
+func NewTwoFactorController() TwoFactorController {
+	userRepository := repositories.NewUserRepository(config.DB)
+	recoveryCodeRepository := repositories.NewRecoveryCodeRepository(config.DB)
+	twoFactorService := services.NewTwoFactorService(userRepository, recoveryCodeRepository, newSessionService())
+
+	return TwoFactorController{
+		TwoFactorService: twoFactorService,
+	}
+}
+
+// EnrollTwoFactor godoc
+// @Summary Start two-factor enrolment
+// @Description Generate a TOTP secret and otpauth URI for an authenticator app. Two-factor authentication is enabled once a code is confirmed.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=response.TwoFactorEnrollmentResponse}
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/me/2fa/enroll [post]
+func (controller *TwoFactorController) EnrollTwoFactor(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	enrollmentResponse, err := controller.TwoFactorService.EnrollTwoFactor(identity)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    enrollmentResponse,
+	})
+}
+
+// ConfirmTwoFactor godoc
+// @Summary Confirm two-factor enrolment
+// @Description Enable two-factor authentication with a code from the authenticator app and return single-use recovery codes
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param payload body request.TwoFactorCodeRequest true "TOTP code"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=response.RecoveryCodesResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/me/2fa/confirm [post]
+func (controller *TwoFactorController) ConfirmTwoFactor(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var codePayload request.TwoFactorCodeRequest
+
+	if !bindJSON(c, &codePayload) {
+		return
+	}
+
+	recoveryCodesResponse, err := controller.TwoFactorService.ConfirmTwoFactor(c.Request.Context(), identity, codePayload, clientInfo(c))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    recoveryCodesResponse,
+	})
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off. Requires the password and a TOTP or recovery code.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param payload body request.TwoFactorDisableRequest true "Password and code"
+// @Security BearerAuth
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/me/2fa/disable [post]
+func (controller *TwoFactorController) DisableTwoFactor(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var disablePayload request.TwoFactorDisableRequest
+
+	if !bindJSON(c, &disablePayload) {
+		return
+	}
+
+	if err := controller.TwoFactorService.DisableTwoFactor(c.Request.Context(), identity, disablePayload, clientInfo(c)); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+	})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace every recovery code. Requires a TOTP code.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param payload body request.TwoFactorCodeRequest true "TOTP code"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=response.RecoveryCodesResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/me/2fa/recovery-codes [post]
+func (controller *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	var codePayload request.TwoFactorCodeRequest
+
+	if !bindJSON(c, &codePayload) {
+		return
+	}
+
+	recoveryCodesResponse, err := controller.TwoFactorService.RegenerateRecoveryCodes(c.Request.Context(), identity, codePayload, clientInfo(c))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    recoveryCodesResponse,
+	})
+}
+
+// LoginTwoFactor godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the MFA token returned by /login and a TOTP or recovery code for an access and refresh token pair
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body request.TwoFactorLoginRequest true "MFA token and code"
+// @Success 200 {object} response.Response{data=response.UserLoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Router /api/v1/login/2fa [post]
+func (controller *TwoFactorController) LoginTwoFactor(c *gin.Context) {
+	var loginPayload request.TwoFactorLoginRequest
+
+	if !bindJSON(c, &loginPayload) {
+		return
+	}
+
+	tokenResponse, err := controller.TwoFactorService.CompleteTwoFactorLogin(c.Request.Context(), loginPayload, clientInfo(c))
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    tokenResponse,
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/radenadri/go-boilerplate/config"
 	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
 	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/delivery/http/middlewares"
 	"github.com/radenadri/go-boilerplate/internal/domain/models"
 	"github.com/radenadri/go-boilerplate/internal/repositories"
 	"github.com/radenadri/go-boilerplate/internal/services"
@@ -20,7 +21,8 @@ type UserController struct {
 
 func NewUserController() UserController {
 	userRepository := repositories.NewUserRepository(config.DB)
-	userService := services.NewUserService(userRepository)
+	roleRepository := repositories.NewRoleRepository(config.DB)
+	userService := services.NewUserService(userRepository, roleRepository, newSessionService())
 
 	return UserController{
 		UserService: userService,
@@ -29,33 +31,85 @@ func NewUserController() UserController {
 
 // GetAllUsers godoc
 // @Summary Get all users
-// @Description Get a list of all users with pagination
+// @Description Get a list of all users with pagination, filtering and sorting
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
-// @Param per_page query int false "Items per page" default(10)
+// @Param per_page query int false "Items per page (max 100)" default(10)
+// @Param sort query string false "Comma separated sort fields, prefix with - for descending (id, name, username, email, created_at, updated_at)" example(-created_at,username)
+// @Param q query string false "Search name, username and email"
+// @Param created_after query string false "Only users created at or after this RFC 3339 time"
+// @Param created_before query string false "Only users created before this RFC 3339 time"
+// @Param include_deleted query bool false "Include soft-deleted users (admin only)" default(false)
+// @Param cursor query string false "Switch to cursor pagination; pass next_cursor from the previous page, or an empty value for the first page"
+// @Param limit query int false "Items per page in cursor mode" default(10)
+// @Param with_total query bool false "Compute total_items in cursor mode" default(false)
 // @Security BearerAuth
+// @Security ApiKeyAuth
 // @Success 200 {object} response.Response
+// @Header 200 {string} Link "RFC 8288 pagination links"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/users [get]
 func (controller *UserController) GetAllUsers(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	pageParams := pkg.NewPageParams(c.Request)
 
-	results, err := controller.UserService.GetAllUsers(page, perPage)
+	var userListQuery request.UserListQuery
 
-	// Logging example using zap
-	config.Logger.Info("Get all users")
+	if !bindQuery(c, &userListQuery) {
+		return
+	}
 
+	filter, err := services.NewUserFilter(userListQuery)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, response.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
+	if filter.IncludeDeleted {
+		if identity, ok := middlewares.CurrentUser(c); !ok || !identity.HasRole(models.RoleAdmin) {
+			_ = c.Error(pkg.NewForbiddenError(response.CodeForbidden, "Only administrators can list deleted users"))
+			return
+		}
+	}
+
+	var results *response.PaginatedResponse[response.UserResponse]
+
+	_, hasCursor := c.GetQuery("cursor")
+	_, hasLimit := c.GetQuery("limit")
+
+	if hasCursor || hasLimit {
+		var cursorQuery request.CursorQuery
+
+		if !bindQuery(c, &cursorQuery) {
+			return
+		}
+
+		desc, err := services.CursorSortDirection(filter)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+
+		results, err = controller.UserService.GetUsersByCursor(cursorQuery, filter, desc, pageParams)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+	} else {
+		results, err = controller.UserService.GetAllUsers(pageParams, filter)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+
+	// Logging example using zap
+	config.Logger.Info("Get all users")
+
+	c.Header("Link", pkg.LinkHeader(results.Links))
 	c.JSON(http.StatusOK, results)
 }
 
@@ -65,81 +119,214 @@ func (controller *UserController) GetAllUsers(c *gin.Context) {
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param user body models.User true "User registration information"
+// @Param user body request.UserRegisterRequest true "User registration information"
 // @Success 201 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Router /api/v1/register [post]
 func (controller *UserController) Register(c *gin.Context) {
-	var userPayload models.User
+	var userRegisterPayload request.UserRegisterRequest
+
+	if !bindJSON(c, &userRegisterPayload) {
+		return
+	}
 
-	if err := c.ShouldBindJSON(&userPayload); err != nil {
-		c.JSON(http.StatusBadRequest, response.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+	userResponse, err := controller.UserService.Register(userRegisterPayload)
+
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	if err := pkg.Validator.Struct(userPayload); err != nil {
-		c.JSON(http.StatusBadRequest, response.Response{
-			Success: false,
-			Errors:  pkg.FormatValidationErrors(err),
-		})
+	c.JSON(http.StatusCreated, response.Response{
+		Success: true,
+		Data:    userResponse,
+	})
+}
+
+// GetUser godoc
+// @Summary Get a user
+// @Description Get a single user by ID
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=response.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/users/{id} [get]
+func (controller *UserController) GetUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
 		return
 	}
 
-	userResponse, err := controller.UserService.Register(userPayload)
+	userResponse, err := controller.UserService.GetUserByID(id)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, response.Response{
+	c.JSON(http.StatusOK, response.Response{
 		Success: true,
 		Data:    userResponse,
 	})
 }
 
-// Login godoc
-// @Summary Login user
-// @Description Authenticate a user and return a JWT token
-// @Tags auth
+// UpdateUser godoc
+// @Summary Update a user
+// @Description Partially update a user by ID. Only the supplied fields are changed.
+// @Tags users
 // @Accept json
 // @Produce json
-// @Param credentials body request.UserLoginRequest true "Login credentials"
+// @Param id path int true "User ID"
+// @Param user body request.UserUpdateRequest true "Fields to update"
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=response.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/users/{id} [patch]
+func (controller *UserController) UpdateUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	controller.updateUser(c, id)
+}
+
+// DeleteUser godoc
+// @Summary Delete a user
+// @Description Soft-delete a user by ID and revoke their sessions, refresh tokens and access tokens. Deleted users are purged after the configured retention period.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
-// @Router /api/v1/login [post]
-func (controller *UserController) Login(c *gin.Context) {
-	var userLoginPayload request.UserLoginRequest
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/users/{id} [delete]
+func (controller *UserController) DeleteUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := controller.UserService.DeleteUser(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+	})
+}
+
+// RestoreUser godoc
+// @Summary Restore a deleted user
+// @Description Restore a soft-deleted user by ID
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=response.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/users/{id}/restore [post]
+func (controller *UserController) RestoreUser(c *gin.Context) {
+	id, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	userResponse, err := controller.UserService.RestoreUser(id)
+
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    userResponse,
+	})
+}
+
+// GetMe godoc
+// @Summary Get current user
+// @Description Get the profile of the authenticated user
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} response.Response{data=response.UserResponse}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /api/v1/me [get]
+func (controller *UserController) GetMe(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	userResponse, err := controller.UserService.GetUserByID(identity.UserID)
 
-	if err := c.ShouldBindJSON(&userLoginPayload); err != nil {
-		c.JSON(http.StatusBadRequest, response.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+	if err != nil {
+		_ = c.Error(err)
 		return
 	}
 
-	if err := pkg.Validator.Struct(userLoginPayload); err != nil {
-		c.JSON(http.StatusBadRequest, response.Response{
-			Success: false,
-			Errors:  pkg.FormatValidationErrors(err),
-		})
+	c.JSON(http.StatusOK, response.Response{
+		Success: true,
+		Data:    userResponse,
+	})
+}
+
+// UpdateMe godoc
+// @Summary Update current user
+// @Description Partially update the profile of the authenticated user
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param user body request.UserUpdateRequest true "Fields to update"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=response.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/v1/me [patch]
+func (controller *UserController) UpdateMe(c *gin.Context) {
+	identity, ok := middlewares.CurrentUser(c)
+	if !ok {
+		_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	controller.updateUser(c, identity.UserID)
+}
+
+func (controller *UserController) updateUser(c *gin.Context, id uint) {
+	var userUpdatePayload request.UserUpdateRequest
+
+	if !bindJSON(c, &userUpdatePayload) {
 		return
 	}
 
-	userResponse, err := controller.UserService.Login(userLoginPayload)
+	userResponse, err := controller.UserService.UpdateUser(id, userUpdatePayload)
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, response.Response{
-			Success: false,
-			Error:   err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -148,3 +335,13 @@ func (controller *UserController) Login(c *gin.Context) {
 		Data:    userResponse,
 	})
 }
+
+func parseUserID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		_ = c.Error(pkg.NewValidationError("invalid_user_id", "Invalid user ID"))
+		return 0, false
+	}
+
+	return uint(id), true
+}
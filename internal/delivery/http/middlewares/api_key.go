@@ -0,0 +1,52 @@
+package middlewares
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator resolves an API key to the identity it acts as.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string, clientIP string) (*models.Identity, error)
+}
+
+// Authenticate accepts either a Bearer access token or an X-API-Key header
+// and stores the resulting identity like AuthenticateJWT does. The
+// Authorization header wins when both are sent.
+func Authenticate(apiKeys APIKeyAuthenticator, sessions SessionToucher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Header.Get("Authorization") != "" {
+			identity, ok := authenticateBearer(c, sessions)
+			if !ok {
+				return
+			}
+
+			c.Set(IdentityContextKey, identity)
+			c.Next()
+			return
+		}
+
+		apiKey := strings.TrimSpace(c.Request.Header.Get(APIKeyHeader))
+		if apiKey == "" {
+			abortUnauthorized(c, "Authorization or X-API-Key header not found", response.CodeAuthorizationMissing)
+			return
+		}
+
+		identity, err := apiKeys.AuthenticateAPIKey(c.Request.Context(), apiKey, c.ClientIP())
+		if err != nil {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(IdentityContextKey, identity)
+
+		c.Next()
+	}
+}
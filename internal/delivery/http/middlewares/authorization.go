@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+// RequireRole allows the request through when the caller holds any of the
+// given roles. It must be mounted after AuthenticateJWT.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return authorize(func(identity *models.Identity) bool {
+		return identity.HasRole(roles...)
+	})
+}
+
+// RequirePermission allows the request through when any of the caller's
+// roles grants one of the given permissions. It must be mounted after
+// AuthenticateJWT.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return authorize(func(identity *models.Identity) bool {
+		return identity.HasPermission(permissions...)
+	})
+}
+
+func authorize(allowed func(identity *models.Identity) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := CurrentUser(c)
+		if !ok {
+			_ = c.Error(pkg.NewUnauthorizedError(response.CodeUnauthorized, "Unauthorized"))
+			c.Abort()
+			return
+		}
+
+		if !allowed(identity) {
+			_ = c.Error(pkg.NewForbiddenError(response.CodeForbidden, "You do not have permission to access this resource"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,32 +1,129 @@
 package middlewares
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 
+	sentrygin "github.com/getsentry/sentry-go/gin"
 	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
 	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/pkg"
 )
 
+const problemContentType = "application/problem+json"
+
+var errorKindStatus = map[pkg.ErrorKind]int{
+	pkg.ErrorKindValidation:      http.StatusBadRequest,
+	pkg.ErrorKindUnauthorized:    http.StatusUnauthorized,
+	pkg.ErrorKindForbidden:       http.StatusForbidden,
+	pkg.ErrorKindNotFound:        http.StatusNotFound,
+	pkg.ErrorKindConflict:        http.StatusConflict,
+	pkg.ErrorKindTooManyRequests: http.StatusTooManyRequests,
+	pkg.ErrorKindInternal:        http.StatusInternalServerError,
+	pkg.ErrorKindUnavailable:     http.StatusServiceUnavailable,
+}
+
 func NotFoundHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, response.Response{
-			Success: false,
-			Error:   "URL not found",
-		})
+		renderError(c, http.StatusNotFound, pkg.NewNotFoundError("route_not_found", "URL not found"))
 	}
 }
 
+// ErrorHandler renders the last error recorded with c.Error. Typed
+// pkg.AppErrors are mapped to their status code and stable error code; any
+// other error is reported as a generic 500 so internal details never leak.
 func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
 		// Only handle errors if there are any
-		if len(c.Errors) > 0 {
-			c.JSON(http.StatusInternalServerError, response.Response{
-				Success: false,
-				Error:   "Internal Server Error",
-			})
+		if len(c.Errors) == 0 || c.Writer.Written() {
 			return
 		}
+
+		appError := pkg.AsAppError(c.Errors.Last().Err)
+
+		status, ok := errorKindStatus[appError.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+
+		if status >= http.StatusInternalServerError {
+			if hub := sentrygin.GetHubFromContext(c); hub != nil {
+				hub.CaptureException(c.Errors.Last().Err)
+			}
+		}
+
+		renderError(c, status, appError)
+	}
+}
+
+// renderError writes appError as application/problem+json when the client
+// asks for it and as the regular response.Response envelope otherwise.
+func renderError(c *gin.Context, status int, appError *pkg.AppError) {
+	if appError.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(appError.RetryAfter.Seconds()))))
+	}
+
+	if !acceptsProblemJSON(c.Request) {
+		c.AbortWithStatusJSON(status, response.Response{
+			Success: false,
+			Error:   appError.Message,
+			Code:    appError.Code,
+			Errors:  appError.Fields,
+		})
+		return
+	}
+
+	problemType := "about:blank"
+	if config.ProblemTypeBaseURL != "" {
+		problemType = strings.TrimSuffix(config.ProblemTypeBaseURL, "/") + "/" + appError.Code
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, response.Problem{
+		Type:     problemType,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   appError.Message,
+		Instance: c.Request.URL.Path,
+		Code:     appError.Code,
+		Errors:   appError.Fields,
+	})
+}
+
+func acceptsProblemJSON(request *http.Request) bool {
+	for _, mediaRange := range strings.Split(request.Header.Get("Accept"), ",") {
+		mediaType, params, _ := strings.Cut(mediaRange, ";")
+		if !strings.EqualFold(strings.TrimSpace(mediaType), problemContentType) {
+			continue
+		}
+
+		// Honour an explicit refusal such as "application/problem+json;q=0"
+		return qualityOf(params) > 0
 	}
+
+	return false
+}
+
+// qualityOf returns the q parameter of a media range (RFC 9110 section
+// 12.4.2), defaulting to 1. A malformed value counts as a refusal.
+func qualityOf(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+
+		quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return 0
+		}
+		return quality
+	}
+
+	return 1
 }
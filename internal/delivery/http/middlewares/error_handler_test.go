@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAcceptsProblemJSON(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"Application/Problem+JSON", true},
+		{"application/json, application/problem+json;q=0.5", true},
+		{"application/problem+json;q=0", false},
+		{"application/problem+json; q=0.0", false},
+		{"application/problem+json;q=0.000", false},
+		{"application/problem+json;q=0.001", true},
+		{"application/problem+json;q=abc", false},
+		{"application/problem+json;charset=utf-8;q=1", true},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Accept", test.accept)
+
+		if got := acceptsProblemJSON(request); got != test.want {
+			t.Errorf("acceptsProblemJSON(%q) = %v, want %v", test.accept, got, test.want)
+		}
+	}
+}
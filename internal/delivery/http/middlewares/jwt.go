@@ -1,49 +1,149 @@
 package middlewares
 
 import (
-	"net/http"
+	"context"
+	"errors"
+	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/radenadri/go-boilerplate/config"
 	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/pkg"
 )
 
-func AuthenticateJWT() gin.HandlerFunc {
-	return func(c *gin.Context) {
+const IdentityContextKey = "identity"
 
-		secretKey := config.JWTSecret
+// SessionToucher records that a session was used.
+type SessionToucher interface {
+	TouchSession(ctx context.Context, sessionID string, client models.ClientInfo) error
+}
 
-		if secretKey == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Response{
-				Success: false,
-				Error:   "JWT_SECRET not found",
-			})
+func AuthenticateJWT(sessions SessionToucher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity, ok := authenticateBearer(c, sessions)
+		if !ok {
 			return
 		}
 
-		authToken := c.Request.Header.Get("Authorization")
-		if authToken == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, response.Response{
-				Success: false,
-				Error:   "Authorization header not found",
-			})
-			return
+		c.Set(IdentityContextKey, identity)
+
+		c.Next()
+	}
+}
+
+// authenticateBearer validates the access token in the Authorization header
+// and records the use on the token's session. On failure the request is
+// aborted and false is returned.
+func authenticateBearer(c *gin.Context, sessions SessionToucher) (*models.Identity, bool) {
+	if pkg.Keys == nil {
+		_ = c.Error(pkg.NewInternalError(errors.New("JWT signing keys not configured")))
+		c.Abort()
+		return nil, false
+	}
+
+	authHeader := c.Request.Header.Get("Authorization")
+	if authHeader == "" {
+		abortUnauthorized(c, "Authorization header not found", response.CodeAuthorizationMissing)
+		return nil, false
+	}
+
+	authToken, ok := parseBearerToken(authHeader)
+	if !ok {
+		abortUnauthorized(c, "Authorization header must use the Bearer scheme", response.CodeAuthorizationScheme)
+		return nil, false
+	}
+
+	claims, err := pkg.ParseAccessToken(authToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, pkg.ErrTokenExpired):
+			abortUnauthorized(c, "Token has expired", response.CodeTokenExpired)
+		case errors.Is(err, pkg.ErrTokenMalformed):
+			abortUnauthorized(c, "Token is malformed", response.CodeTokenMalformed)
+		case errors.Is(err, pkg.ErrTokenSignatureInvalid):
+			abortUnauthorized(c, "Token signature is invalid", response.CodeTokenSignature)
+		default:
+			abortUnauthorized(c, "Invalid token", response.CodeTokenInvalid)
 		}
+		return nil, false
+	}
 
-		token, err := jwt.Parse(authToken, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secretKey), nil
-		})
+	revoked, err := pkg.RevokedTokens.IsRevoked(c.Request.Context(), claims.ID)
+	if err != nil {
+		_ = c.Error(pkg.NewInternalError(err))
+		c.Abort()
+		return nil, false
+	}
+
+	if revoked {
+		abortUnauthorized(c, "Token has been revoked", response.CodeTokenRevoked)
+		return nil, false
+	}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, response.Response{
-				Success: false,
-				Error:   "Invalid token",
-			})
+	if claims.SessionID != "" {
+		sessionRevoked, err := pkg.RevokedTokens.IsRevoked(c.Request.Context(), pkg.SessionRevocationID(claims.SessionID))
+		if err != nil {
+			_ = c.Error(pkg.NewInternalError(err))
 			c.Abort()
-			return
+			return nil, false
 		}
 
-		c.Next()
+		if sessionRevoked {
+			abortUnauthorized(c, "Session has been revoked", response.CodeSessionRevoked)
+			return nil, false
+		}
+	}
+
+	revokedBefore, err := pkg.RevokedTokens.UserTokensRevokedBefore(c.Request.Context(), claims.UserID)
+	if err != nil {
+		_ = c.Error(pkg.NewInternalError(err))
+		c.Abort()
+		return nil, false
+	}
+
+	if claims.IssuedAt != nil && claims.IssuedAt.Time.Before(revokedBefore) {
+		abortUnauthorized(c, "Token has been revoked", response.CodeTokenRevoked)
+		return nil, false
+	}
+
+	if claims.SessionID != "" {
+		client := models.ClientInfo{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+		if err := sessions.TouchSession(c.Request.Context(), claims.SessionID, client); err != nil {
+			_ = c.Error(pkg.NewInternalError(err))
+			c.Abort()
+			return nil, false
+		}
+	}
+
+	return claims.Identity(), true
+}
+
+// CurrentUser returns the identity stored by AuthenticateJWT or Authenticate. The boolean is
+// false when the route is not behind an authentication middleware.
+func CurrentUser(c *gin.Context) (*models.Identity, bool) {
+	value, exists := c.Get(IdentityContextKey)
+	if !exists {
+		return nil, false
+	}
+
+	identity, ok := value.(*models.Identity)
+	return identity, ok && identity != nil
+}
+
+// parseBearerToken extracts the credentials from an RFC 6750 "Bearer" header.
+// The scheme is matched case-insensitively.
+func parseBearerToken(header string) (string, bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
 	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func abortUnauthorized(c *gin.Context, message string, code string) {
+	c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+	_ = c.Error(pkg.NewUnauthorizedError(code, message))
+	c.Abort()
 }
@@ -0,0 +1,77 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+type recordingSessionToucher struct {
+	sessionIDs []string
+	clients    []models.ClientInfo
+}
+
+func (t *recordingSessionToucher) TouchSession(ctx context.Context, sessionID string, client models.ClientInfo) error {
+	t.sessionIDs = append(t.sessionIDs, sessionID)
+	t.clients = append(t.clients, client)
+	return nil
+}
+
+func TestAuthenticateJWTTouchesSession(t *testing.T) {
+	defer func(keys *pkg.KeySet, revoked pkg.TokenRevocationStore, method, secret string) {
+		pkg.Keys, pkg.RevokedTokens = keys, revoked
+		config.JWTSigningMethod, config.JWTSecret = method, secret
+	}(pkg.Keys, pkg.RevokedTokens, config.JWTSigningMethod, config.JWTSecret)
+
+	config.JWTSigningMethod, config.JWTSecret = "HS256", "test-secret"
+	if err := pkg.InitSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+	pkg.RevokedTokens = pkg.NewMemoryRevocationStore()
+
+	user := models.User{Username: "jane"}
+	user.ID = 7
+
+	gin.SetMode(gin.TestMode)
+
+	for _, sessionID := range []string{"session-id", ""} {
+		token, err := pkg.GenerateJWT(user, sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sessions := &recordingSessionToucher{}
+		router := gin.New()
+		router.GET("/me", AuthenticateJWT(sessions), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", "test-agent")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+
+		if sessionID == "" {
+			if len(sessions.sessionIDs) != 0 {
+				t.Errorf("token without a session touched %v", sessions.sessionIDs)
+			}
+			continue
+		}
+
+		if len(sessions.sessionIDs) != 1 || sessions.sessionIDs[0] != sessionID {
+			t.Fatalf("touched sessions = %v, want [%s]", sessions.sessionIDs, sessionID)
+		}
+		if sessions.clients[0].UserAgent != "test-agent" {
+			t.Errorf("touched with user agent %q", sessions.clients[0].UserAgent)
+		}
+	}
+}
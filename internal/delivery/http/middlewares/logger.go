@@ -24,7 +24,7 @@ func Logger() gin.HandlerFunc {
 				config.Logger.Error(e)
 			}
 		} else {
-			config.Logger.Info("Request processed",
+			fields := []zap.Field{
 				zap.String("method", c.Request.Method),
 				zap.String("path", path),
 				zap.String("query", query),
@@ -32,7 +32,13 @@ func Logger() gin.HandlerFunc {
 				zap.Duration("latency", latency),
 				zap.String("ip", c.ClientIP()),
 				zap.String("user-agent", c.Request.UserAgent()),
-			)
+			}
+
+			if identity, ok := CurrentUser(c); ok {
+				fields = append(fields, zap.Uint("user_id", identity.UserID))
+			}
+
+			config.Logger.Info("Request processed", fields...)
 		}
 	}
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/getsentry/sentry-go"
@@ -12,6 +13,7 @@ import (
 	_ "github.com/radenadri/go-boilerplate/docs"
 	"github.com/radenadri/go-boilerplate/internal/delivery/http/controllers"
 	"github.com/radenadri/go-boilerplate/internal/delivery/http/middlewares"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"go.uber.org/zap"
@@ -35,6 +37,10 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func InitRouter() *gin.Engine {
 
 	if err := sentry.Init(sentry.ClientOptions{
@@ -60,6 +66,12 @@ func InitRouter() *gin.Engine {
 
 	r := gin.Default()
 
+	// Only the proxies listed in TRUSTED_PROXIES may set X-Forwarded-For;
+	// otherwise clients could pick the IP their login failures count against
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		panic(fmt.Errorf("invalid TRUSTED_PROXIES: %w", err))
+	}
+
 	// Initialize Sentry's handler
 	r.Use(sentrygin.New(sentrygin.Options{
 		Repanic: true,
@@ -84,16 +96,35 @@ func InitRouter() *gin.Engine {
 	// Apply CORS middleware with default configuration
 	r.Use(middlewares.CORS(middlewares.DefaultCORSConfig()))
 
-	api := r.Group(fmt.Sprintf("/api/%s", config.AppApiVersion))
-
 	// Initialize controllers
 	userController := controllers.NewUserController()
+	authController := controllers.NewAuthController()
+	sessionController := controllers.NewSessionController()
+	emailVerificationController := controllers.NewEmailVerificationController()
+	passwordController := controllers.NewPasswordController()
+	twoFactorController := controllers.NewTwoFactorController()
+	apiKeyController := controllers.NewAPIKeyController()
+	oauthController := controllers.NewOAuthController()
+	jwksController := controllers.NewJWKSController()
+
+	// Well-known endpoints live outside of the versioned API
+	r.GET("/.well-known/jwks.json", jwksController.GetJWKS)
+
+	api := r.Group(fmt.Sprintf("/api/%s", config.AppApiVersion))
 
 	// Public routes
 	public := api.Group("")
 	{
-		public.POST("/login", userController.Login)
+		public.POST("/login", authController.Login)
+		public.POST("/login/2fa", twoFactorController.LoginTwoFactor)
 		public.POST("/register", userController.Register)
+		public.POST("/refresh", sessionController.Refresh)
+		public.POST("/verify-email", emailVerificationController.VerifyEmail)
+		public.POST("/verify-email/resend", emailVerificationController.ResendVerificationEmail)
+		public.POST("/password/forgot", passwordController.ForgotPassword)
+		public.POST("/password/reset", passwordController.ResetPassword)
+		public.GET("/auth/:provider/authorize", oauthController.StartOAuthLogin)
+		public.POST("/auth/:provider/callback", oauthController.CompleteOAuthLogin)
 
 		// Test Sentry
 		public.GET("/foo", func(ctx *gin.Context) {
@@ -101,12 +132,55 @@ func InitRouter() *gin.Engine {
 		})
 	}
 
-	// Protected routes
+	// Account routes only accept user access tokens, so an API key cannot
+	// change credentials or mint further keys
+	account := api.Group("")
+	account.Use(middlewares.AuthenticateJWT(sessionController.SessionService))
+	{
+		account.POST("/logout", sessionController.Logout)
+		account.PATCH("/me", userController.UpdateMe)
+		account.POST("/me/password", passwordController.ChangePassword)
+		account.GET("/me/sessions", sessionController.GetSessions)
+		account.DELETE("/me/sessions/:id", sessionController.RevokeSession)
+		account.POST("/me/2fa/enroll", twoFactorController.EnrollTwoFactor)
+		account.POST("/me/2fa/confirm", twoFactorController.ConfirmTwoFactor)
+		account.POST("/me/2fa/disable", twoFactorController.DisableTwoFactor)
+		account.POST("/me/2fa/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+		account.GET("/me/api-keys", apiKeyController.GetAPIKeys)
+		account.POST("/me/api-keys", apiKeyController.CreateAPIKey)
+		account.GET("/me/api-keys/:id", apiKeyController.GetAPIKey)
+		account.PATCH("/me/api-keys/:id", apiKeyController.UpdateAPIKey)
+		account.DELETE("/me/api-keys/:id", apiKeyController.DeleteAPIKey)
+	}
+
+	// Protected routes accept a user access token or an API key
 	protected := api.Group("")
-	protected.Use(middlewares.AuthenticateJWT())
+	protected.Use(middlewares.Authenticate(apiKeyController.APIKeyService, sessionController.SessionService))
 	{
-		protected.GET("/users", userController.GetAllUsers)
+		protected.GET("/me", userController.GetMe)
+
+		protected.GET("/users", middlewares.RequirePermission(models.PermissionUsersRead), userController.GetAllUsers)
+		protected.GET("/users/:id", middlewares.RequirePermission(models.PermissionUsersRead), userController.GetUser)
+		protected.PATCH("/users/:id", middlewares.RequirePermission(models.PermissionUsersWrite), userController.UpdateUser)
+		protected.DELETE("/users/:id", middlewares.RequirePermission(models.PermissionUsersDelete), userController.DeleteUser)
+		protected.POST("/users/:id/restore", middlewares.RequireRole(models.RoleAdmin), userController.RestoreUser)
+
+		protected.GET("/admin/lockouts", middlewares.RequireRole(models.RoleAdmin), authController.GetLoginLockouts)
+		protected.DELETE("/admin/lockouts/:scope/:subject", middlewares.RequireRole(models.RoleAdmin), authController.ClearLoginLockout)
 	}
 
 	return r
 }
+
+// trustedProxies parses the comma-separated TRUSTED_PROXIES setting. An empty
+// list trusts no proxy, so c.ClientIP() is the connecting address.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(config.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// APIKey lets a machine client act on behalf of its owner, limited to the
+// permissions listed in Scopes. Only the SHA-256 hash of the key is stored;
+// Prefix is kept in clear so owners can tell their keys apart.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-" sensitive:"true"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// GrantedPermissions returns the scopes the owner still holds. Taking a
+// permission away from the owner takes it away from their keys as well.
+func (k APIKey) GrantedPermissions(owner User) []string {
+	ownerPermissions := owner.PermissionNames()
+	granted := make([]string, 0, len(k.Scopes))
+	for _, scope := range k.Scopes {
+		if slices.Contains(ownerPermissions, scope) {
+			granted = append(granted, scope)
+		}
+	}
+
+	return granted
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Identity describes the authenticated caller of a request.
+type Identity struct {
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Roles       []string  `json:"roles"`
+	Permissions []string  `json:"permissions"`
+	TokenID     string    `json:"-"`
+	SessionID   string    `json:"-"`
+	APIKeyID    uint      `json:"-"`
+	ExpiresAt   time.Time `json:"-"`
+}
+
+func (i *Identity) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(i.Roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *Identity) HasPermission(permissions ...string) bool {
+	for _, permission := range permissions {
+		if slices.Contains(i.Permissions, permission) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LinkedAccount ties a user to their account at an external identity
+// provider. Subject is the provider's stable user ID ("sub" claim).
+type LinkedAccount struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
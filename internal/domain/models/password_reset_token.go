@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// PasswordResetToken stores the SHA-256 hash of an emailed reset token, never
+// the token itself.
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id"`
+	TokenHash string     `json:"-" sensitive:"true"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
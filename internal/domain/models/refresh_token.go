@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type RefreshToken struct {
+	ID        string     `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id"`
+	FamilyID  string     `json:"family_id"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RotatedAt *time.Time `json:"rotated_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Session is one login on one device. Its ID is the refresh token family
+// created at login and is carried by access tokens as the "sid" claim.
+type Session struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id"`
+	Device     string     `json:"device"`
+	IPAddress  string     `json:"ip_address"`
+	UserAgent  string     `json:"user_agent"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ClientInfo describes where a request came from.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
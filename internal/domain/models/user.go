@@ -3,17 +3,24 @@ package models
 import (
 	"encoding/json"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
-	ID        uint       `json:"id" gorm:"primaryKey"`
-	Name      string     `json:"name" validate:"required,min=3"`
-	Username  string     `json:"username" gorm:"unique" validate:"required,min=3,max=30"`
-	Email     string     `json:"email" gorm:"unique" validate:"required,email"`
-	Password  string     `json:"password" validate:"required,min=8,max=32"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Name             string         `json:"name" validate:"required,min=3"`
+	Username         string         `json:"username" validate:"required,min=3,max=30"`
+	Email            string         `json:"email" validate:"required,email"`
+	Password         string         `json:"-" sensitive:"true"`
+	Roles            []Role         `json:"roles,omitempty" gorm:"many2many:user_roles"`
+	EmailVerifiedAt  *time.Time     `json:"email_verified_at"`
+	TOTPSecret       string         `json:"-" gorm:"column:totp_secret" sensitive:"true"`
+	TOTPEnabledAt    *time.Time     `json:"totp_enabled_at" gorm:"column:totp_enabled_at"`
+	TOTPLastUsedStep int64          `json:"-" gorm:"column:totp_last_used_step"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"index" swaggertype:"string"`
 }
 
 func (u User) MarshalJSON() ([]byte, error) {
@@ -28,10 +35,41 @@ func (u User) MarshalJSON() ([]byte, error) {
 		CreatedAt: u.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt: u.UpdatedAt.Format("2006-01-02 15:04:05"),
 		DeletedAt: func() string {
-			if u.DeletedAt != nil {
-				return u.DeletedAt.Format("2006-01-02 15:04:05")
+			if u.DeletedAt.Valid {
+				return u.DeletedAt.Time.Format("2006-01-02 15:04:05")
 			}
 			return ""
 		}(),
 	})
 }
+
+func (u User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+
+	return names
+}
+
+// PermissionNames returns the distinct permissions granted through all of the
+// user's roles. Roles must be preloaded with their permissions.
+func (u User) PermissionNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	for _, role := range u.Roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				names = append(names, permission.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+func (u User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
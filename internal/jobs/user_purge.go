@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/internal/services"
+	"github.com/radenadri/go-boilerplate/utils"
+	"go.uber.org/zap"
+)
+
+// StartUserPurge periodically hard-deletes users that have been soft-deleted
+// for longer than USER_PURGE_RETENTION. It runs until ctx is cancelled.
+func StartUserPurge(ctx context.Context) {
+	userPurgeService := services.NewUserPurgeService(repositories.NewUserRepository(config.DB))
+
+	interval := utils.ParseDurationOrDefault(config.UserPurgeInterval, 24*time.Hour)
+	retention := utils.ParseDurationOrDefault(config.UserPurgeRetention, 30*24*time.Hour)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := userPurgeService.PurgeDeletedUsers(retention)
+			if err != nil {
+				config.Logger.Error("Failed to purge deleted users", zap.Error(err))
+			} else if purged > 0 {
+				config.Logger.Info("Purged deleted users", zap.Int64("count", purged))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
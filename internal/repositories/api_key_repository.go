@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type GormAPIKeyRepository struct {
+	DB *gorm.DB
+}
+
+type APIKeyRepository interface {
+	Create(apiKey *models.APIKey) error
+	FindByHash(keyHash string) (*models.APIKey, error)
+	FindByUser(userID uint) ([]models.APIKey, error)
+	FindByIDForUser(id uint, userID uint) (*models.APIKey, error)
+	Update(apiKey *models.APIKey) error
+	Delete(id uint, userID uint) error
+	Touch(id uint, clientIP string, usedBefore time.Time) error
+}
+
+func NewAPIKeyRepository(DB *gorm.DB) APIKeyRepository {
+	return &GormAPIKeyRepository{DB: DB}
+}
+
+func (r *GormAPIKeyRepository) Create(apiKey *models.APIKey) error {
+	if err := r.DB.Create(apiKey).Error; err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (r *GormAPIKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+
+	if err := r.DB.Where("key_hash = ?", keyHash).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *GormAPIKeyRepository) FindByUser(userID uint) ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+
+	if err := r.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (r *GormAPIKeyRepository) FindByIDForUser(id uint, userID uint) (*models.APIKey, error) {
+	var apiKey models.APIKey
+
+	if err := r.DB.Where("id = ? AND user_id = ?", id, userID).First(&apiKey).Error; err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *GormAPIKeyRepository) Update(apiKey *models.APIKey) error {
+	if err := r.DB.Model(apiKey).Select("name", "scopes").Updates(apiKey).Error; err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// Delete removes one of the user's keys. It returns gorm.ErrRecordNotFound
+// when the user has no such key.
+func (r *GormAPIKeyRepository) Delete(id uint, userID uint) error {
+	result := r.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.APIKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Touch records a use of the key unless it was already recorded after
+// usedBefore, so busy keys do not cause a write on every request.
+func (r *GormAPIKeyRepository) Touch(id uint, clientIP string, usedBefore time.Time) error {
+	return r.DB.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, usedBefore).
+		UpdateColumns(map[string]interface{}{
+			"last_used_at": time.Now(),
+			"last_used_ip": clientIP,
+		}).Error
+}
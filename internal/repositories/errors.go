@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+// SQLSTATE codes for the integrity constraint violations we translate.
+const (
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+// constraintKeyPattern pulls the column list out of Postgres error details
+// such as `Key (username)=(john) already exists.`
+var constraintKeyPattern = regexp.MustCompile(`Key \((.+?)\)=\(`)
+
+// expressionColumnPattern unwraps expression index keys such as
+// `lower((username)::text)` to the column they are built on.
+var expressionColumnPattern = regexp.MustCompile(`^\w+\(\(*(\w+)\)*(::\w+)?\)$`)
+
+// fieldInitialisms are the column name parts written in capitals in Go field
+// names, e.g. user_id becomes UserID.
+var fieldInitialisms = map[string]bool{
+	"api":  true,
+	"id":   true,
+	"ip":   true,
+	"jwt":  true,
+	"mfa":  true,
+	"totp": true,
+	"url":  true,
+}
+
+// translateError turns Postgres constraint violations into conflict
+// AppErrors that name the offending columns. Other errors are returned
+// unchanged.
+func translateError(err error) error {
+	var pgError *pgconn.PgError
+	if !errors.As(err, &pgError) {
+		return err
+	}
+
+	switch pgError.Code {
+	case pgUniqueViolation:
+		return pkg.NewConflictError("duplicate_value", "Resource already exists",
+			constraintFields(pgError, "unique", "This value is already in use")...).Wrap(err)
+	case pgForeignKeyViolation:
+		return pkg.NewConflictError("reference_violation", "Referenced resource does not exist or is still in use",
+			constraintFields(pgError, "foreign_key", "This value references a missing or dependent resource")...).Wrap(err)
+	case pgNotNullViolation:
+		return pkg.NewConflictError("missing_value", "A required value is missing",
+			constraintFields(pgError, "not_null", "This field is required")...).Wrap(err)
+	case pgCheckViolation:
+		return pkg.NewConflictError("check_violation", "A value does not satisfy a constraint",
+			constraintFields(pgError, "check", "This value is not allowed")...).Wrap(err)
+	default:
+		return err
+	}
+}
+
+func constraintFields(pgError *pgconn.PgError, rule string, reason string) []response.ValidationError {
+	var columns []string
+
+	if pgError.ColumnName != "" {
+		columns = []string{pgError.ColumnName}
+	} else if match := constraintKeyPattern.FindStringSubmatch(pgError.Detail); match != nil {
+		columns = strings.Split(match[1], ", ")
+	}
+
+	fields := make([]response.ValidationError, 0, len(columns))
+	for _, column := range columns {
+		if match := expressionColumnPattern.FindStringSubmatch(column); match != nil {
+			column = match[1]
+		}
+
+		fields = append(fields, response.ValidationError{
+			Field:  fieldName(column),
+			Rule:   rule,
+			Reason: reason,
+		})
+	}
+
+	return fields
+}
+
+// fieldName turns a snake_case column into the Go field name validation
+// errors report, so clients see "Email" from both the validator and the
+// database.
+func fieldName(column string) string {
+	var builder strings.Builder
+	for _, part := range strings.Split(column, "_") {
+		if part == "" {
+			continue
+		}
+		if fieldInitialisms[part] {
+			builder.WriteString(strings.ToUpper(part))
+			continue
+		}
+		builder.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+func TestTranslateErrorReportsFieldNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		pgError    *pgconn.PgError
+		wantCode   string
+		wantFields []string
+	}{
+		{
+			name:       "unique column",
+			pgError:    &pgconn.PgError{Code: pgUniqueViolation, Detail: "Key (username)=(john) already exists."},
+			wantCode:   "duplicate_value",
+			wantFields: []string{"Username"},
+		},
+		{
+			name:       "case-insensitive unique index",
+			pgError:    &pgconn.PgError{Code: pgUniqueViolation, Detail: "Key (lower(email::text))=(john@example.com) already exists."},
+			wantCode:   "duplicate_value",
+			wantFields: []string{"Email"},
+		},
+		{
+			name:       "composite key",
+			pgError:    &pgconn.PgError{Code: pgUniqueViolation, Detail: "Key (provider, subject)=(google, 123) already exists."},
+			wantCode:   "duplicate_value",
+			wantFields: []string{"Provider", "Subject"},
+		},
+		{
+			name:       "foreign key with initialism",
+			pgError:    &pgconn.PgError{Code: pgForeignKeyViolation, Detail: "Key (user_id)=(42) is not present in table \"users\"."},
+			wantCode:   "reference_violation",
+			wantFields: []string{"UserID"},
+		},
+		{
+			name:       "not null column",
+			pgError:    &pgconn.PgError{Code: pgNotNullViolation, ColumnName: "last_used_ip"},
+			wantCode:   "missing_value",
+			wantFields: []string{"LastUsedIP"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var appError *pkg.AppError
+			if !errors.As(translateError(tt.pgError), &appError) {
+				t.Fatal("translateError() did not return an AppError")
+			}
+			if appError.Code != tt.wantCode {
+				t.Errorf("code = %s, want %s", appError.Code, tt.wantCode)
+			}
+			if len(appError.Fields) != len(tt.wantFields) {
+				t.Fatalf("fields = %+v, want %v", appError.Fields, tt.wantFields)
+			}
+			for i, field := range appError.Fields {
+				if field.Field != tt.wantFields[i] {
+					t.Errorf("field %d = %s, want %s", i, field.Field, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTranslateErrorPassesOtherErrorsThrough(t *testing.T) {
+	err := errors.New("connection refused")
+	if got := translateError(err); got != err {
+		t.Errorf("translateError() = %v, want the original error", got)
+	}
+}
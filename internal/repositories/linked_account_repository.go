@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type GormLinkedAccountRepository struct {
+	DB *gorm.DB
+}
+
+type LinkedAccountRepository interface {
+	Create(linkedAccount *models.LinkedAccount) error
+	FindByProviderSubject(provider string, subject string) (*models.LinkedAccount, error)
+}
+
+func NewLinkedAccountRepository(DB *gorm.DB) LinkedAccountRepository {
+	return &GormLinkedAccountRepository{DB: DB}
+}
+
+func (r *GormLinkedAccountRepository) Create(linkedAccount *models.LinkedAccount) error {
+	if err := r.DB.Create(linkedAccount).Error; err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (r *GormLinkedAccountRepository) FindByProviderSubject(provider string, subject string) (*models.LinkedAccount, error) {
+	var linkedAccount models.LinkedAccount
+
+	if err := r.DB.Where("provider = ? AND subject = ?", provider, subject).First(&linkedAccount).Error; err != nil {
+		return nil, err
+	}
+
+	return &linkedAccount, nil
+}
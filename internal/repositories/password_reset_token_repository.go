@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type GormPasswordResetTokenRepository struct {
+	DB *gorm.DB
+}
+
+type PasswordResetTokenRepository interface {
+	Create(token *models.PasswordResetToken) error
+	FindByHash(tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(id uint) (bool, error)
+	InvalidateByUser(userID uint) error
+}
+
+func NewPasswordResetTokenRepository(DB *gorm.DB) PasswordResetTokenRepository {
+	return &GormPasswordResetTokenRepository{DB: DB}
+}
+
+func (r *GormPasswordResetTokenRepository) Create(token *models.PasswordResetToken) error {
+	if err := r.DB.Create(token).Error; err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (r *GormPasswordResetTokenRepository) FindByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+
+	if err := r.DB.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed consumes a reset token. It reports false when the token was
+// already used or has expired, so two concurrent resets cannot both succeed.
+func (r *GormPasswordResetTokenRepository) MarkUsed(id uint) (bool, error) {
+	now := time.Now()
+
+	result := r.DB.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateByUser consumes every outstanding reset token of the user.
+func (r *GormPasswordResetTokenRepository) InvalidateByUser(userID uint) error {
+	return r.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type GormRecoveryCodeRepository struct {
+	DB *gorm.DB
+}
+
+type RecoveryCodeRepository interface {
+	ReplaceForUser(userID uint, codeHashes []string) error
+	Consume(userID uint, codeHash string) (bool, error)
+	DeleteByUser(userID uint) error
+}
+
+func NewRecoveryCodeRepository(DB *gorm.DB) RecoveryCodeRepository {
+	return &GormRecoveryCodeRepository{DB: DB}
+}
+
+// ReplaceForUser swaps every recovery code of the user for the given ones in
+// a single transaction.
+func (r *GormRecoveryCodeRepository) ReplaceForUser(userID uint, codeHashes []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]models.RecoveryCode, 0, len(codeHashes))
+		for _, codeHash := range codeHashes {
+			codes = append(codes, models.RecoveryCode{UserID: userID, CodeHash: codeHash})
+		}
+
+		if err := tx.Create(&codes).Error; err != nil {
+			return translateError(err)
+		}
+
+		return nil
+	})
+}
+
+// Consume marks an unused recovery code as used and reports whether one
+// matched.
+func (r *GormRecoveryCodeRepository) Consume(userID uint, codeHash string) (bool, error) {
+	result := r.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *GormRecoveryCodeRepository) DeleteByUser(userID uint) error {
+	return r.DB.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
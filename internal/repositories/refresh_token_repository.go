@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type GormRefreshTokenRepository struct {
+	DB *gorm.DB
+}
+
+type RefreshTokenRepository interface {
+	Create(token *models.RefreshToken) error
+	FindByID(id string) (*models.RefreshToken, error)
+	MarkRotated(id string) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeByUser(userID uint) error
+}
+
+func NewRefreshTokenRepository(DB *gorm.DB) RefreshTokenRepository {
+	return &GormRefreshTokenRepository{DB: DB}
+}
+
+func (r *GormRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	if err := r.DB.Create(token).Error; err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (r *GormRefreshTokenRepository) FindByID(id string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+
+	if err := r.DB.Where("id = ?", id).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkRotated flags a refresh token as used. It reports false when the token
+// had already been rotated or revoked, which callers must treat as reuse.
+func (r *GormRefreshTokenRepository) MarkRotated(id string) (bool, error) {
+	result := r.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", id).
+		Update("rotated_at", time.Now())
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *GormRefreshTokenRepository) RevokeFamily(familyID string) error {
+	if err := r.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *GormRefreshTokenRepository) RevokeByUser(userID uint) error {
+	if err := r.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+type GormRoleRepository struct {
+	DB *gorm.DB
+}
+
+type RoleRepository interface {
+	FindByName(name string) (*models.Role, error)
+}
+
+func NewRoleRepository(DB *gorm.DB) RoleRepository {
+	return &GormRoleRepository{DB: DB}
+}
+
+func (r *GormRoleRepository) FindByName(name string) (*models.Role, error) {
+	var role models.Role
+
+	if err := r.DB.Where("name = ?", name).First(&role).Error; err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/utils"
+	"gorm.io/gorm"
+)
+
+type GormSessionRepository struct {
+	DB *gorm.DB
+}
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	FindActiveByUser(userID uint) ([]models.Session, error)
+	Extend(id string, client models.ClientInfo, expiresAt time.Time) error
+	Touch(id string, client models.ClientInfo, seenBefore time.Time) error
+	Revoke(id string, userID uint) (*models.Session, error)
+	RevokeByUser(userID uint) error
+}
+
+func NewSessionRepository(DB *gorm.DB) SessionRepository {
+	return &GormSessionRepository{DB: DB}
+}
+
+func (r *GormSessionRepository) Create(session *models.Session) error {
+	if err := r.DB.Create(session).Error; err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// FindActiveByUser returns the sessions that are neither revoked nor expired,
+// most recently used first.
+func (r *GormSessionRepository) FindActiveByUser(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+
+	if err := r.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Extend records that the session was refreshed from the given client and
+// moves its expiry to expiresAt.
+func (r *GormSessionRepository) Extend(id string, client models.ClientInfo, expiresAt time.Time) error {
+	columns := sessionClientColumns(client)
+	columns["expires_at"] = expiresAt
+
+	return r.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumns(columns).Error
+}
+
+// Touch records that the session was just used from the given client unless
+// that was already recorded after seenBefore, so busy sessions do not cause
+// a write on every request.
+func (r *GormSessionRepository) Touch(id string, client models.ClientInfo, seenBefore time.Time) error {
+	return r.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL AND last_seen_at < ?", id, seenBefore).
+		UpdateColumns(sessionClientColumns(client)).Error
+}
+
+func sessionClientColumns(client models.ClientInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"device":       utils.DescribeUserAgent(client.UserAgent),
+		"ip_address":   client.IPAddress,
+		"user_agent":   client.UserAgent,
+		"last_seen_at": time.Now(),
+	}
+}
+
+// Revoke marks a session of the given user as revoked and returns it. It
+// returns gorm.ErrRecordNotFound when the user has no such active session.
+func (r *GormSessionRepository) Revoke(id string, userID uint) (*models.Session, error) {
+	var session models.Session
+
+	if err := r.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).First(&session).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := r.DB.Model(&session).Update("revoked_at", now).Error; err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *GormSessionRepository) RevokeByUser(userID uint) error {
+	return r.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
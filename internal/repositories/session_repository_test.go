@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+)
+
+func TestSessionRepositoryTouchIsConditionalAndUpdatesDevice(t *testing.T) {
+	var statements []string
+	repository := NewSessionRepository(dryRunDB(t, func(sql string) { statements = append(statements, sql) }))
+
+	client := models.ClientInfo{IPAddress: "192.0.2.1", UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) Chrome/120.0"}
+	if err := repository.Touch("session-id", client, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(statements) != 1 {
+		t.Fatalf("expected one update statement, got %v", statements)
+	}
+
+	sql := statements[0]
+	if !strings.Contains(sql, `"device"=`) {
+		t.Errorf("touch does not update the device: %s", sql)
+	}
+	if !strings.Contains(sql, "last_seen_at < ") {
+		t.Errorf("touch is not throttled on last_seen_at: %s", sql)
+	}
+	if strings.Contains(sql, `"expires_at"=`) {
+		t.Errorf("touch extends the session: %s", sql)
+	}
+}
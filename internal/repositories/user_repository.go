@@ -1,39 +1,129 @@
 package repositories
 
 import (
+	"strings"
+	"time"
+
 	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/pkg"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormUserRepository struct {
 	DB *gorm.DB
 }
 
+// UserSortableColumns maps the sort keys accepted from clients to columns.
+// Anything not listed here is rejected so clients cannot order by arbitrary
+// SQL.
+var UserSortableColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"username":   "username",
+	"email":      "email",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// UserKeyset is a position in the (created_at, id) ordering used by keyset
+// pagination.
+type UserKeyset struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// UserFilter narrows and orders the users returned by Paginate, FindAfter
+// and Count.
+type UserFilter struct {
+	Search         string
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	Sort           []SortField
+	IncludeDeleted bool
+}
+
 type UserRepository interface {
-	FindAll(limit int, offset int) ([]models.User, error)
+	Paginate(filter UserFilter, params pkg.PageParams) ([]models.User, int64, error)
+	FindAfter(filter UserFilter, after *UserKeyset, desc bool, limit int) ([]models.User, error)
+	FindByID(id uint) (*models.User, error)
 	FindByUsername(username string) (*models.User, error)
+	FindByEmail(email string) (*models.User, error)
 	Create(user *models.User) error
-	Count() (int64, error)
+	Update(user *models.User, columns ...string) error
+	Delete(id uint) error
+	Restore(id uint) error
+	MarkEmailVerified(id uint, email string) (bool, error)
+	MarkTOTPStepUsed(id uint, step int64) (bool, error)
+	Purge(deletedBefore time.Time) (int64, error)
+	Count(filter UserFilter) (int64, error)
 }
 
 func NewUserRepository(DB *gorm.DB) UserRepository {
 	return &GormUserRepository{DB: DB}
 }
 
-func (r *GormUserRepository) FindAll(limit int, offset int) ([]models.User, error) {
+func (r *GormUserRepository) Paginate(filter UserFilter, params pkg.PageParams) ([]models.User, int64, error) {
+	return pkg.Paginate[models.User](r.sorted(r.filtered(filter), filter.Sort), params)
+}
+
+// FindAfter returns up to limit users ordered by (created_at, id) that come
+// strictly after the given position. A nil position starts from the top.
+func (r *GormUserRepository) FindAfter(filter UserFilter, after *UserKeyset, desc bool, limit int) ([]models.User, error) {
 	var results []models.User
 
-	if err := r.DB.Limit(limit).Offset(offset).Find(&results).Error; err != nil {
+	query := r.filtered(filter)
+
+	if after != nil {
+		operator := ">"
+		if desc {
+			operator = "<"
+		}
+		query = query.Where("(created_at, id) "+operator+" (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	if err := query.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "created_at"}, Desc: desc}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc}).
+		Limit(limit).
+		Find(&results).Error; err != nil {
 		return nil, err
 	}
 
 	return results, nil
 }
 
+func (r *GormUserRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+
+	if err := r.DB.Preload("Roles.Permissions").Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// FindByUsername and FindByEmail match case-insensitively, backed by the
+// unique LOWER() indexes on both columns.
 func (r *GormUserRepository) FindByUsername(username string) (*models.User, error) {
 	var user models.User
 
-	if err := r.DB.Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.DB.Preload("Roles.Permissions").Where("LOWER(username) = LOWER(?)", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *GormUserRepository) FindByEmail(email string) (*models.User, error) {
+	var user models.User
+
+	if err := r.DB.Preload("Roles.Permissions").Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 
@@ -42,18 +132,193 @@ func (r *GormUserRepository) FindByUsername(username string) (*models.User, erro
 
 func (r *GormUserRepository) Create(user *models.User) error {
 	if err := r.DB.Create(&user).Error; err != nil {
-		return err
+		return translateError(err)
+	}
+
+	return nil
+}
+
+// Update writes only the given columns of user, plus updated_at. Saving the
+// whole row would overwrite concurrent changes to other columns and bring
+// back a user that was deleted in the meantime; a deleted user instead
+// reports gorm.ErrRecordNotFound.
+func (r *GormUserRepository) Update(user *models.User, columns ...string) error {
+	user.UpdatedAt = time.Now()
+
+	selected := append([]string{"updated_at"}, columns...)
+	result := r.DB.Model(user).Omit(clause.Associations).Select(selected).Updates(user)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *GormUserRepository) Delete(id uint) error {
+	result := r.DB.Delete(&models.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Restore clears DeletedAt on a soft-deleted user. It fails with a conflict
+// when a live user has taken the username or email since.
+func (r *GormUserRepository) Restore(id uint) error {
+	result := r.DB.Unscoped().Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		// Someone may have taken the username or email in the meantime
+		return translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 
 	return nil
 }
 
-func (r *GormUserRepository) Count() (int64, error) {
+// MarkEmailVerified sets email_verified_at if the user still has the given
+// email and has not been verified yet. It reports whether a row was updated,
+// which makes a verification link usable only once.
+func (r *GormUserRepository) MarkEmailVerified(id uint, email string) (bool, error) {
+	result := r.DB.Model(&models.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", id, email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// MarkTOTPStepUsed records the time step of an accepted TOTP code. It
+// reports false when that step or a later one was already used, so a code
+// cannot be replayed within its validity window.
+func (r *GormUserRepository) MarkTOTPStepUsed(id uint, step int64) (bool, error) {
+	result := r.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_used_step < ?", id, step).
+		Update("totp_last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// userDependents are the models whose rows belong to a single user.
+var userDependents = []interface{}{
+	&models.RefreshToken{},
+	&models.Session{},
+	&models.APIKey{},
+	&models.RecoveryCode{},
+	&models.PasswordResetToken{},
+	&models.LinkedAccount{},
+}
+
+// Purge permanently removes users that were soft-deleted before the given
+// time and returns how many were removed. Their tokens, sessions, keys, codes,
+// linked accounts and roles are deleted in the same transaction instead of
+// relying on the foreign keys to cascade. The first statement locks the users,
+// so one restored meanwhile either waits for the purge or is left alone.
+func (r *GormUserRepository) Purge(deletedBefore time.Time) (int64, error) {
+	var purged int64
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		purgeable := tx.Unscoped().Model(&models.User{}).
+			Select("id").
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Clauses(clause.Locking{Strength: "UPDATE"})
+
+		for _, dependent := range userDependents {
+			if err := tx.Where("user_id IN (?)", purgeable).Delete(dependent).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec("DELETE FROM user_roles WHERE user_id IN (?)", purgeable).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Delete(&models.User{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func (r *GormUserRepository) Count(filter UserFilter) (int64, error) {
 	var totalItems int64
 
-	if err := r.DB.Model(&models.User{}).Count(&totalItems).Error; err != nil {
+	if err := r.filtered(filter).Model(&models.User{}).Count(&totalItems).Error; err != nil {
 		return 0, err
 	}
 
 	return totalItems, nil
 }
+
+func (r *GormUserRepository) filtered(filter UserFilter) *gorm.DB {
+	query := r.DB
+
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+
+	if filter.Search != "" {
+		pattern := "%" + escapeLike(filter.Search) + "%"
+		query = query.Where("(name ILIKE ? OR username ILIKE ? OR email ILIKE ?)", pattern, pattern, pattern)
+	}
+
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	return query
+}
+
+// sorted applies the whitelisted sort fields and always ends with the primary
+// key so pages are stable when the other columns tie.
+func (r *GormUserRepository) sorted(query *gorm.DB, sort []SortField) *gorm.DB {
+	sortedByID := false
+
+	for _, field := range sort {
+		column, ok := UserSortableColumns[field.Field]
+		if !ok {
+			continue
+		}
+
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: field.Desc})
+		sortedByID = sortedByID || column == "id"
+	}
+
+	if !sortedByID {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}})
+	}
+
+	return query
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
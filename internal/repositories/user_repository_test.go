@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/pkg"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds SQL without a database and hands every update statement to
+// capture.
+func dryRunDB(t *testing.T, capture func(sql string)) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Callback().Update().After("gorm:update").Register("test:capture", func(tx *gorm.DB) {
+		capture(tx.Statement.SQL.String())
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestUserRepositoryUpdateWritesOnlyGivenColumns(t *testing.T) {
+	var statements []string
+	repository := NewUserRepository(dryRunDB(t, func(sql string) { statements = append(statements, sql) }))
+
+	user := &models.User{Name: "Jane", Username: "jane", Email: "jane@example.com", Password: "hash", Roles: []models.Role{{Name: models.RoleUser}}}
+	user.ID = 7
+
+	// A dry run affects no rows, which is what a concurrently deleted user
+	// looks like
+	if err := repository.Update(user, "password"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Update() error = %v, want gorm.ErrRecordNotFound", err)
+	}
+
+	if len(statements) != 1 {
+		t.Fatalf("expected one update statement, got %v", statements)
+	}
+
+	sql := statements[0]
+	if !strings.Contains(sql, `SET "password"=$1,"updated_at"=$2 WHERE`) {
+		t.Errorf("update writes more than the password: %s", sql)
+	}
+	if !strings.Contains(sql, `"users"."deleted_at" IS NULL`) {
+		t.Errorf("update can touch a soft-deleted user: %s", sql)
+	}
+}
+
+func TestUserRepositoryRestoreReportsTakenEmail(t *testing.T) {
+	db := dryRunDB(t, func(sql string) {})
+
+	// Answer the restore the way Postgres does when a live user has the
+	// address now
+	if err := db.Callback().Update().After("gorm:update").Register("test:unique_violation", func(tx *gorm.DB) {
+		_ = tx.AddError(&pgconn.PgError{
+			Code:   pgUniqueViolation,
+			Detail: "Key (lower((email)::text))=(jane@example.com) already exists.",
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewUserRepository(db).Restore(7)
+
+	var appError *pkg.AppError
+	if !errors.As(err, &appError) || appError.Kind != pkg.ErrorKindConflict {
+		t.Fatalf("Restore() error = %v, want a conflict", err)
+	}
+	if len(appError.Fields) != 1 || appError.Fields[0].Field != "Email" {
+		t.Errorf("conflict fields = %+v, want Email", appError.Fields)
+	}
+}
+
+// recordingConnPool stands in for a database, recording every statement run
+// through it and whether the transaction they ran in was committed.
+type recordingConnPool struct {
+	statements []string
+	committed  bool
+}
+
+func (p *recordingConnPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("unexpected prepare")
+}
+
+func (p *recordingConnPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.statements = append(p.statements, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (p *recordingConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (p *recordingConnPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func (p *recordingConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &recordingTx{ConnPool: p, pool: p}, nil
+}
+
+type recordingTx struct {
+	gorm.ConnPool
+	pool *recordingConnPool
+}
+
+func (tx *recordingTx) Commit() error {
+	tx.pool.committed = true
+	return nil
+}
+
+func (tx *recordingTx) Rollback() error {
+	return nil
+}
+
+func TestUserRepositoryPurgeDeletesDependents(t *testing.T) {
+	pool := &recordingConnPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := NewUserRepository(db).Purge(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged != 1 {
+		t.Errorf("Purge() = %d, want 1", purged)
+	}
+	if !pool.committed {
+		t.Error("purge was not committed as one transaction")
+	}
+
+	wantTables := []string{"refresh_tokens", "sessions", "api_keys", "recovery_codes", "password_reset_tokens", "linked_accounts", "user_roles", "users"}
+	if len(pool.statements) != len(wantTables) {
+		t.Fatalf("statements = %q, want one delete per table in %v", pool.statements, wantTables)
+	}
+
+	for i, table := range wantTables {
+		statement := pool.statements[i]
+		if !strings.HasPrefix(strings.ReplaceAll(statement, `"`, ""), "DELETE FROM "+table+" ") {
+			t.Errorf("statement %d = %s, want a delete from %s", i, statement, table)
+			continue
+		}
+		if table != "users" && !strings.Contains(statement, "FOR UPDATE") {
+			t.Errorf("%s delete does not lock the purged users: %s", table, statement)
+		}
+	}
+}
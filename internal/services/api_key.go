@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/mapper"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyNotFound = pkg.NewNotFoundError("api_key_not_found", "API key not found")
+	ErrInvalidAPIKey  = pkg.NewUnauthorizedError("api_key_invalid", "Invalid API key")
+	ErrAPIKeyExpired  = pkg.NewUnauthorizedError("api_key_expired", "API key has expired")
+
+	ErrInvalidAPIKeyExpiry = pkg.NewValidationError("invalid_api_key_expiry", "API key expiry must be in the future", response.ValidationError{
+		Field:  "ExpiresAt",
+		Rule:   "future",
+		Reason: "Must be in the future",
+	})
+)
+
+// apiKeyTouchInterval limits how often last-used tracking writes to the
+// database for a busy key.
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyService manages the caller's API keys and resolves keys presented on
+// requests.
+type APIKeyService struct {
+	APIKeyRepository repositories.APIKeyRepository
+	UserRepository   repositories.UserRepository
+}
+
+func NewAPIKeyService(apiKeyRepository repositories.APIKeyRepository, userRepository repositories.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		APIKeyRepository: apiKeyRepository,
+		UserRepository:   userRepository,
+	}
+}
+
+// CreateAPIKey issues a key for the caller. The key itself is only part of
+// this response; afterwards just its prefix is shown.
+func (s *APIKeyService) CreateAPIKey(identity *models.Identity, createPayload request.CreateAPIKeyRequest) (*response.APIKeyCreatedResponse, error) {
+	if createPayload.ExpiresAt != nil && !createPayload.ExpiresAt.After(time.Now()) {
+		return nil, ErrInvalidAPIKeyExpiry
+	}
+
+	if err := s.checkAPIKeyScopes(identity.UserID, createPayload.Scopes); err != nil {
+		return nil, err
+	}
+
+	key, prefix, keyHash, err := pkg.GenerateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := models.APIKey{
+		UserID:    identity.UserID,
+		Name:      createPayload.Name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(createPayload.Scopes))),
+		ExpiresAt: createPayload.ExpiresAt,
+	}
+
+	if err := s.APIKeyRepository.Create(&apiKey); err != nil {
+		return nil, err
+	}
+
+	return &response.APIKeyCreatedResponse{
+		APIKeyResponse: *mapper.ToAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (s *APIKeyService) GetAPIKeys(identity *models.Identity) ([]response.APIKeyResponse, error) {
+	apiKeys, err := s.APIKeyRepository.FindByUser(identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.ToAPIKeyResponses(apiKeys), nil
+}
+
+func (s *APIKeyService) GetAPIKey(identity *models.Identity, id uint) (*response.APIKeyResponse, error) {
+	apiKey, err := s.findAPIKey(identity.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.ToAPIKeyResponse(*apiKey), nil
+}
+
+func (s *APIKeyService) UpdateAPIKey(identity *models.Identity, id uint, updatePayload request.UpdateAPIKeyRequest) (*response.APIKeyResponse, error) {
+	apiKey, err := s.findAPIKey(identity.UserID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updatePayload.Name != nil {
+		apiKey.Name = *updatePayload.Name
+	}
+
+	if len(updatePayload.Scopes) > 0 {
+		if err := s.checkAPIKeyScopes(identity.UserID, updatePayload.Scopes); err != nil {
+			return nil, err
+		}
+		apiKey.Scopes = slices.Compact(slices.Sorted(slices.Values(updatePayload.Scopes)))
+	}
+
+	if err := s.APIKeyRepository.Update(apiKey); err != nil {
+		return nil, err
+	}
+
+	return mapper.ToAPIKeyResponse(*apiKey), nil
+}
+
+// DeleteAPIKey revokes a key immediately.
+func (s *APIKeyService) DeleteAPIKey(identity *models.Identity, id uint) error {
+	if err := s.APIKeyRepository.Delete(id, identity.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey resolves a key presented in X-API-Key to the identity
+// of its owner, restricted to the key's scopes. API key identities carry no
+// roles, so role-gated routes stay reserved for signed-in users.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key string, clientIP string) (*models.Identity, error) {
+	apiKey, err := s.APIKeyRepository.FindByHash(pkg.HashToken(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if apiKey.Expired(now) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	user, err := findUser(s.UserRepository, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if err := s.APIKeyRepository.Touch(apiKey.ID, clientIP, now.Add(-apiKeyTouchInterval)); err != nil {
+		return nil, err
+	}
+
+	identity := &models.Identity{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		Roles:       []string{},
+		Permissions: apiKey.GrantedPermissions(*user),
+		APIKeyID:    apiKey.ID,
+	}
+	if apiKey.ExpiresAt != nil {
+		identity.ExpiresAt = *apiKey.ExpiresAt
+	}
+
+	return identity, nil
+}
+
+func (s *APIKeyService) findAPIKey(userID uint, id uint) (*models.APIKey, error) {
+	apiKey, err := s.APIKeyRepository.FindByIDForUser(id, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+// checkAPIKeyScopes refuses scopes the owner does not hold, so a key can
+// never do more than its owner.
+func (s *APIKeyService) checkAPIKeyScopes(userID uint, scopes []string) error {
+	user, err := findUser(s.UserRepository, userID)
+	if err != nil {
+		return err
+	}
+
+	ownerPermissions := user.PermissionNames()
+	var validationErrors []response.ValidationError
+	for _, scope := range scopes {
+		if !slices.Contains(ownerPermissions, scope) {
+			validationErrors = append(validationErrors, response.ValidationError{
+				Field:  "Scopes",
+				Rule:   "scope",
+				Value:  scope,
+				Reason: "Must be a permission you hold",
+			})
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return pkg.NewValidationError("invalid_api_key_scope", "API key scopes must be permissions you hold", validationErrors...)
+	}
+
+	return nil
+}
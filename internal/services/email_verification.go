@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/mapper"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidVerificationToken = pkg.NewValidationError("verification_token_invalid", "Invalid or expired verification token")
+	ErrEmailNotVerified         = pkg.NewForbiddenError("email_not_verified", "Email address has not been verified")
+)
+
+// EmailVerificationService confirms email addresses and resends verification
+// links.
+type EmailVerificationService struct {
+	UserRepository repositories.UserRepository
+}
+
+func NewEmailVerificationService(userRepository repositories.UserRepository) *EmailVerificationService {
+	return &EmailVerificationService{UserRepository: userRepository}
+}
+
+// VerifyEmail marks the user's email as verified. A token can only be used
+// once and only while the user still has the address it was issued for.
+func (s *EmailVerificationService) VerifyEmail(ctx context.Context, verifyEmailPayload request.VerifyEmailRequest) (*response.UserResponse, error) {
+	claims, err := pkg.ParseEmailVerificationToken(verifyEmailPayload.Token)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	revoked, err := pkg.RevokedTokens.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	verified, err := s.UserRepository.MarkEmailVerified(uint(userID), claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if err := pkg.RevokedTokens.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	user, err := findUser(s.UserRepository, uint(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.ToUserResponse(*user), nil
+}
+
+// ResendVerificationEmail sends a new link to an unverified account. It
+// succeeds silently for unknown or already verified addresses so the
+// endpoint cannot be used to discover accounts; mail failures are only
+// logged for the same reason.
+func (s *EmailVerificationService) ResendVerificationEmail(ctx context.Context, resendPayload request.ResendVerificationEmailRequest) error {
+	user, err := s.UserRepository.FindByEmail(resendPayload.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	notifyEmailVerification(ctx, *user)
+	return nil
+}
+
+func sendVerificationEmail(ctx context.Context, user models.User) error {
+	token, err := pkg.GenerateEmailVerificationToken(user)
+	if err != nil {
+		return err
+	}
+
+	link, err := tokenLink(config.EmailVerificationURL, token)
+	if err != nil {
+		return err
+	}
+
+	return pkg.Mail.Send(ctx, pkg.MailMessage{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Name, link, pkg.EmailVerificationTTL(),
+		),
+	})
+}
+
+// notifyEmailVerification sends a verification email in the background, so a
+// slow mail server never holds up the surrounding request and failures are
+// only logged; the user can always ask for a new link.
+func notifyEmailVerification(ctx context.Context, user models.User) {
+	go func() {
+		if err := sendVerificationEmail(context.WithoutCancel(ctx), user); err != nil {
+			config.Logger.Error("Failed to send verification email", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}()
+}
+
+func requireVerifiedEmail() bool {
+	required, err := strconv.ParseBool(config.AuthRequireVerifiedEmail)
+	return err == nil && required
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+// blockingMailer holds every message until release is closed and drops it
+// when ctx is done by then.
+type blockingMailer struct {
+	release chan struct{}
+	sent    chan pkg.MailMessage
+}
+
+func (m *blockingMailer) Send(ctx context.Context, message pkg.MailMessage) error {
+	<-m.release
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.sent <- message
+	return nil
+}
+
+func TestResendVerificationEmailDoesNotWaitForMail(t *testing.T) {
+	useMemoryStores(t)
+	defer func(mailer pkg.Mailer) { pkg.Mail = mailer }(pkg.Mail)
+	mailer := &blockingMailer{release: make(chan struct{}), sent: make(chan pkg.MailMessage, 1)}
+	pkg.Mail = mailer
+
+	user := models.User{Name: "Jane", Username: "jane", Email: "jane@example.com"}
+	user.ID = 7
+	service := &EmailVerificationService{UserRepository: newFakeUserRepository(user)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- service.ResendVerificationEmail(ctx, request.ResendVerificationEmailRequest{Email: "jane@example.com"})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ResendVerificationEmail() waited for the mail server")
+	}
+
+	// The request ending must not abort the delivery
+	cancel()
+	close(mailer.release)
+
+	select {
+	case message := <-mailer.sent:
+		if message.To != "jane@example.com" {
+			t.Errorf("mail sent to %q, want jane@example.com", message.To)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("verification email was never sent")
+	}
+}
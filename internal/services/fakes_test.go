@@ -0,0 +1,256 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// fakeUserRepository keeps users in memory. Methods the tests do not need
+// are left to the embedded interface and panic when called.
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users map[uint]*models.User
+}
+
+func newFakeUserRepository(users ...models.User) *fakeUserRepository {
+	repository := &fakeUserRepository{users: make(map[uint]*models.User)}
+	for i := range users {
+		repository.users[users[i].ID] = &users[i]
+	}
+
+	return repository
+}
+
+func (r *fakeUserRepository) find(match func(user *models.User) bool) (*models.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeUserRepository) FindByID(id uint) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return user.ID == id })
+}
+
+func (r *fakeUserRepository) FindByUsername(username string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return strings.EqualFold(user.Username, username) })
+}
+
+func (r *fakeUserRepository) FindByEmail(email string) (*models.User, error) {
+	return r.find(func(user *models.User) bool { return strings.EqualFold(user.Email, email) })
+}
+
+func (r *fakeUserRepository) Create(user *models.User) error {
+	user.ID = uint(len(r.users) + 1)
+	stored := *user
+	r.users[user.ID] = &stored
+
+	return nil
+}
+
+type fakeRecoveryCodeRepository struct {
+	repositories.RecoveryCodeRepository
+}
+
+func (r *fakeRecoveryCodeRepository) Consume(userID uint, codeHash string) (bool, error) {
+	return false, nil
+}
+
+type fakeRoleRepository struct {
+	repositories.RoleRepository
+}
+
+func (r *fakeRoleRepository) FindByName(name string) (*models.Role, error) {
+	return &models.Role{Name: name}, nil
+}
+
+type fakeLinkedAccountRepository struct {
+	repositories.LinkedAccountRepository
+	linkedAccounts []models.LinkedAccount
+}
+
+func (r *fakeLinkedAccountRepository) Create(linkedAccount *models.LinkedAccount) error {
+	linkedAccount.ID = uint(len(r.linkedAccounts) + 1)
+	r.linkedAccounts = append(r.linkedAccounts, *linkedAccount)
+
+	return nil
+}
+
+func (r *fakeLinkedAccountRepository) FindByProviderSubject(provider string, subject string) (*models.LinkedAccount, error) {
+	for _, linkedAccount := range r.linkedAccounts {
+		if linkedAccount.Provider == provider && linkedAccount.Subject == subject {
+			found := linkedAccount
+			return &found, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+type fakeRefreshTokenRepository struct {
+	repositories.RefreshTokenRepository
+	tokens map[string]*models.RefreshToken
+}
+
+func newFakeRefreshTokenRepository() *fakeRefreshTokenRepository {
+	return &fakeRefreshTokenRepository{tokens: make(map[string]*models.RefreshToken)}
+}
+
+func (r *fakeRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	stored := *token
+	r.tokens[token.ID] = &stored
+
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) FindByID(id string) (*models.RefreshToken, error) {
+	token, ok := r.tokens[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	found := *token
+	return &found, nil
+}
+
+func (r *fakeRefreshTokenRepository) MarkRotated(id string) (bool, error) {
+	token, ok := r.tokens[id]
+	if !ok || token.RotatedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	token.RotatedAt = &now
+	return true, nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.revoke(func(token *models.RefreshToken) bool { return token.FamilyID == familyID })
+}
+
+func (r *fakeRefreshTokenRepository) RevokeByUser(userID uint) error {
+	return r.revoke(func(token *models.RefreshToken) bool { return token.UserID == userID })
+}
+
+func (r *fakeRefreshTokenRepository) revoke(match func(token *models.RefreshToken) bool) error {
+	now := time.Now()
+	for _, token := range r.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
+
+type fakeSessionRepository struct {
+	repositories.SessionRepository
+	sessions map[string]*models.Session
+}
+
+func newFakeSessionRepository() *fakeSessionRepository {
+	return &fakeSessionRepository{sessions: make(map[string]*models.Session)}
+}
+
+func (r *fakeSessionRepository) Create(session *models.Session) error {
+	stored := *session
+	r.sessions[session.ID] = &stored
+
+	return nil
+}
+
+func (r *fakeSessionRepository) FindActiveByUser(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(time.Now()) {
+			sessions = append(sessions, *session)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *fakeSessionRepository) Extend(id string, client models.ClientInfo, expiresAt time.Time) error {
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.ExpiresAt = expiresAt
+	}
+
+	return nil
+}
+
+func (r *fakeSessionRepository) Touch(id string, client models.ClientInfo, seenBefore time.Time) error {
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil && session.LastSeenAt.Before(seenBefore) {
+		session.LastSeenAt = time.Now()
+	}
+
+	return nil
+}
+
+func (r *fakeSessionRepository) Revoke(id string, userID uint) (*models.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	revoked := *session
+	return &revoked, nil
+}
+
+func (r *fakeSessionRepository) RevokeByUser(userID uint) error {
+	now := time.Now()
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
+
+// useMemoryStores points the token, revocation and login throttle globals at
+// in-process implementations for the duration of the test.
+func useMemoryStores(t *testing.T) {
+	t.Helper()
+
+	previousKeys, previousRevoked, previousAttempts := pkg.Keys, pkg.RevokedTokens, pkg.LoginAttempts
+	previousMethod, previousSecret := config.JWTSigningMethod, config.JWTSecret
+	t.Cleanup(func() {
+		pkg.Keys, pkg.RevokedTokens, pkg.LoginAttempts = previousKeys, previousRevoked, previousAttempts
+		config.JWTSigningMethod, config.JWTSecret = previousMethod, previousSecret
+	})
+
+	config.JWTSigningMethod = "HS256"
+	config.JWTSecret = "test-secret"
+	if err := pkg.InitSigningKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg.RevokedTokens = pkg.NewMemoryRevocationStore()
+	pkg.LoginAttempts = pkg.NewMemoryLoginThrottle()
+}
+
+// testPasswordHash hashes with the minimum bcrypt cost so tests stay fast.
+func testPasswordHash(t *testing.T, password string) string {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(hash)
+}
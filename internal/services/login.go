@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+// AuthService signs users in with a password and manages login lockouts.
+type AuthService struct {
+	UserRepository repositories.UserRepository
+	Sessions       *SessionService
+}
+
+func NewAuthService(userRepository repositories.UserRepository, sessions *SessionService) *AuthService {
+	return &AuthService{
+		UserRepository: userRepository,
+		Sessions:       sessions,
+	}
+}
+
+// Login authenticates by username or email. Unknown users and wrong
+// passwords get the same error, and failed attempts are throttled per account
+// (or per identifier when there is no account) and per client IP.
+func (s *AuthService) Login(ctx context.Context, userLoginPayload request.UserLoginRequest, client models.ClientInfo) (*response.UserLoginResponse, error) {
+	identifier := userLoginPayload.Identifier()
+
+	user, err := findUserByIdentifier(s.UserRepository, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := throttleSubject(user, identifier)
+	if err := checkLoginLockout(ctx, subject, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		pkg.CheckPasswordHash(userLoginPayload.Password, timingPasswordHash)
+		if err := recordLoginFailure(ctx, subject, client.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if !pkg.CheckPasswordHash(userLoginPayload.Password, user.Password) {
+		if err := recordLoginFailure(ctx, subject, client.IPAddress); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := pkg.LoginAttempts.Reset(ctx, pkg.LoginScopeUsername, subject); err != nil {
+		return nil, err
+	}
+
+	if requireVerifiedEmail() && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.Sessions.completeFirstFactor(*user, client)
+}
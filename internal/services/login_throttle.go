@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidCredentials   = pkg.NewUnauthorizedError("invalid_credentials", "Invalid credentials")
+	ErrTooManyLoginAttempts = pkg.NewTooManyRequestsError("too_many_login_attempts", "Too many failed login attempts, try again later", 0)
+	ErrInvalidLockoutScope  = pkg.NewValidationError("invalid_lockout_scope", "Lockout scope must be username or ip")
+)
+
+// timingPasswordHash is compared against when the user does not exist so a
+// failed login takes as long whether or not the account is real.
+const timingPasswordHash = "$2a$14$BOwuxjvOnQUw.ESBf8EEa.kxQEqAc86.KbDm4IOtDSrmpMZ4j2TeG"
+
+// throttleSubject names the bucket failed logins are counted in: the account
+// when the identifier resolves to one, so its username, email and second
+// factor share a single counter, and otherwise the normalized identifier.
+func throttleSubject(user *models.User, identifier string) string {
+	if user != nil {
+		return accountThrottleSubject(user.ID)
+	}
+
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+func accountThrottleSubject(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+// findUserByIdentifier looks a user up by username or email and returns nil
+// when there is none.
+func findUserByIdentifier(userRepository repositories.UserRepository, identifier string) (*models.User, error) {
+	// Usernames cannot contain "@", so anything with one is an email
+	findUser := userRepository.FindByUsername
+	if strings.Contains(identifier, "@") {
+		findUser = userRepository.FindByEmail
+	}
+
+	user, err := findUser(identifier)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	return user, err
+}
+
+// checkLoginLockout refuses the attempt while either the throttle subject or
+// the client IP is locked out.
+func checkLoginLockout(ctx context.Context, subject string, clientIP string) error {
+	subjects := map[string]string{
+		pkg.LoginScopeUsername: subject,
+		pkg.LoginScopeIP:       clientIP,
+	}
+
+	for scope, subject := range subjects {
+		lockedFor, err := pkg.LoginAttempts.LockedFor(ctx, scope, subject)
+		if err != nil {
+			return err
+		}
+
+		if lockedFor > 0 {
+			return pkg.NewTooManyRequestsError(ErrTooManyLoginAttempts.Code, ErrTooManyLoginAttempts.Message, lockedFor)
+		}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt against the throttle subject and
+// the client IP.
+func recordLoginFailure(ctx context.Context, subject string, clientIP string) error {
+	if _, err := pkg.LoginAttempts.RecordFailure(ctx, pkg.LoginScopeUsername, subject); err != nil {
+		return err
+	}
+
+	_, err := pkg.LoginAttempts.RecordFailure(ctx, pkg.LoginScopeIP, clientIP)
+	return err
+}
+
+// verifyThrottled checks a credential of a known account, such as its current
+// password or a second factor, under the login throttle, so neither an MFA
+// token nor a stolen access token can be used to guess it. A failed check
+// counts towards the account's lockout and a successful one clears it.
+func verifyThrottled(ctx context.Context, user *models.User, client models.ClientInfo, verify func() (bool, error)) (bool, error) {
+	subject := accountThrottleSubject(user.ID)
+	if err := checkLoginLockout(ctx, subject, client.IPAddress); err != nil {
+		return false, err
+	}
+
+	valid, err := verify()
+	if err != nil {
+		return false, err
+	}
+
+	if !valid {
+		return false, recordLoginFailure(ctx, subject, client.IPAddress)
+	}
+
+	return true, pkg.LoginAttempts.Reset(ctx, pkg.LoginScopeUsername, subject)
+}
+
+func (s *AuthService) GetLoginLockouts(ctx context.Context) ([]pkg.LoginLockout, error) {
+	lockouts, err := pkg.LoginAttempts.Lockouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if lockouts == nil {
+		lockouts = []pkg.LoginLockout{}
+	}
+
+	return lockouts, nil
+}
+
+// ClearLoginLockout lifts a lockout and forgets the failed attempts behind it.
+// A username or email is resolved to the account it belongs to, since
+// existing accounts are throttled under "user:<id>".
+func (s *AuthService) ClearLoginLockout(ctx context.Context, scope string, subject string) error {
+	if !pkg.IsLoginScope(scope) {
+		return ErrInvalidLockoutScope
+	}
+
+	if scope == pkg.LoginScopeUsername && !strings.HasPrefix(subject, "user:") {
+		user, err := findUserByIdentifier(s.UserRepository, subject)
+		if err != nil {
+			return err
+		}
+
+		subject = throttleSubject(user, subject)
+	}
+
+	return pkg.LoginAttempts.Reset(ctx, scope, subject)
+}
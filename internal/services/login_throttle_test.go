@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+func lockedFor(t *testing.T, subject string) time.Duration {
+	t.Helper()
+
+	lockout, err := pkg.LoginAttempts.LockedFor(context.Background(), pkg.LoginScopeUsername, subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return lockout
+}
+
+func TestLoginThrottlesPerAccount(t *testing.T) {
+	useMemoryStores(t)
+	defer func(limit string) { config.LoginMaxAttempts = limit }(config.LoginMaxAttempts)
+	config.LoginMaxAttempts = "2"
+
+	user := models.User{Username: "Jane", Email: "jane@example.com", Password: testPasswordHash(t, "Passw0rd!")}
+	user.ID = 7
+	service := &AuthService{UserRepository: newFakeUserRepository(user)}
+
+	ctx := context.Background()
+	client := models.ClientInfo{IPAddress: "192.0.2.1"}
+
+	// Username and email of the same account share one counter
+	for _, login := range []string{"jane", "JANE@example.com"} {
+		_, err := service.Login(ctx, request.UserLoginRequest{Login: login, Password: "wrong"}, client)
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("Login(%s) error = %v, want invalid credentials", login, err)
+		}
+	}
+
+	if lockedFor(t, "user:7") == 0 {
+		t.Error("account is not locked after failures through username and email")
+	}
+	if lockedFor(t, "jane") != 0 || lockedFor(t, "jane@example.com") != 0 {
+		t.Error("failures for an existing account were charged to the identifier")
+	}
+
+	_, err := service.Login(ctx, request.UserLoginRequest{Login: "jane", Password: "Passw0rd!"}, client)
+	var appError *pkg.AppError
+	if !errors.As(err, &appError) || appError.Code != ErrTooManyLoginAttempts.Code {
+		t.Errorf("Login() on a locked account error = %v, want too many attempts", err)
+	}
+}
+
+func TestLoginThrottlesUnknownIdentifier(t *testing.T) {
+	useMemoryStores(t)
+	defer func(limit string) { config.LoginMaxAttempts = limit }(config.LoginMaxAttempts)
+	config.LoginMaxAttempts = "1"
+
+	service := &AuthService{UserRepository: newFakeUserRepository()}
+
+	_, err := service.Login(context.Background(), request.UserLoginRequest{Login: " Ghost ", Password: "wrong"}, models.ClientInfo{IPAddress: "192.0.2.1"})
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login() error = %v, want invalid credentials", err)
+	}
+
+	if lockedFor(t, "ghost") == 0 {
+		t.Error("unknown identifier is not throttled under its normalized form")
+	}
+}
+
+func TestTwoFactorFailuresChargeTheAccount(t *testing.T) {
+	useMemoryStores(t)
+	defer func(limit string) { config.LoginMaxAttempts = limit }(config.LoginMaxAttempts)
+	config.LoginMaxAttempts = "1"
+
+	enabledAt := time.Now()
+	user := models.User{Username: "jane", Email: "jane@example.com", TOTPSecret: "JBSWY3DPEHPK3PXP", TOTPEnabledAt: &enabledAt}
+	user.ID = 7
+	service := &TwoFactorService{UserRepository: newFakeUserRepository(user), RecoveryCodeRepository: &fakeRecoveryCodeRepository{}}
+
+	mfaToken, err := pkg.GenerateMFAPendingToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.CompleteTwoFactorLogin(context.Background(), request.TwoFactorLoginRequest{MFAToken: mfaToken, Code: "AAAA-BBBB"}, models.ClientInfo{IPAddress: "192.0.2.1"})
+	if !errors.Is(err, ErrTwoFactorLoginFailed) {
+		t.Fatalf("CompleteTwoFactorLogin() error = %v, want two-factor failure", err)
+	}
+
+	if lockedFor(t, "user:7") == 0 {
+		t.Error("second factor failure was not charged to the account")
+	}
+}
+
+func TestChangePasswordThrottlesCurrentPassword(t *testing.T) {
+	useMemoryStores(t)
+	defer func(limit string) { config.LoginMaxAttempts = limit }(config.LoginMaxAttempts)
+	config.LoginMaxAttempts = "1"
+
+	user := models.User{Username: "jane", Email: "jane@example.com", Password: testPasswordHash(t, "Passw0rd!")}
+	user.ID = 7
+	service := &PasswordService{UserRepository: newFakeUserRepository(user)}
+
+	identity := &models.Identity{UserID: 7}
+	client := models.ClientInfo{IPAddress: "192.0.2.1"}
+	payload := request.ChangePasswordRequest{CurrentPassword: "wrong", Password: "N3w-Passw0rd", PasswordConfirmation: "N3w-Passw0rd"}
+
+	_, err := service.ChangePassword(context.Background(), identity, payload, client)
+	if !errors.Is(err, ErrCurrentPasswordIncorrect) {
+		t.Fatalf("ChangePassword() error = %v, want %v", err, ErrCurrentPasswordIncorrect)
+	}
+
+	// Even the right password is refused while the account is locked
+	payload.CurrentPassword = "Passw0rd!"
+	_, err = service.ChangePassword(context.Background(), identity, payload, client)
+	var appError *pkg.AppError
+	if !errors.As(err, &appError) || appError.Code != ErrTooManyLoginAttempts.Code {
+		t.Errorf("ChangePassword() on a locked account error = %v, want too many attempts", err)
+	}
+}
+
+func TestTwoFactorManagementThrottlesCodes(t *testing.T) {
+	enabledAt := time.Now()
+	enabled := models.User{Username: "jane", Email: "jane@example.com", TOTPSecret: "JBSWY3DPEHPK3PXP", TOTPEnabledAt: &enabledAt}
+	enrolled := models.User{Username: "jane", Email: "jane@example.com", TOTPSecret: "JBSWY3DPEHPK3PXP"}
+
+	tests := map[string]struct {
+		user    models.User
+		attempt func(ctx context.Context, service *TwoFactorService, identity *models.Identity, client models.ClientInfo) error
+		wantErr error
+	}{
+		"confirm": {
+			user: enrolled,
+			attempt: func(ctx context.Context, service *TwoFactorService, identity *models.Identity, client models.ClientInfo) error {
+				_, err := service.ConfirmTwoFactor(ctx, identity, request.TwoFactorCodeRequest{Code: "000000"}, client)
+				return err
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		"regenerate recovery codes": {
+			user: enabled,
+			attempt: func(ctx context.Context, service *TwoFactorService, identity *models.Identity, client models.ClientInfo) error {
+				_, err := service.RegenerateRecoveryCodes(ctx, identity, request.TwoFactorCodeRequest{Code: "000000"}, client)
+				return err
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		"disable with a wrong code": {
+			user: enabled,
+			attempt: func(ctx context.Context, service *TwoFactorService, identity *models.Identity, client models.ClientInfo) error {
+				return service.DisableTwoFactor(ctx, identity, request.TwoFactorDisableRequest{Password: "Passw0rd!", Code: "AAAA-BBBB"}, client)
+			},
+			wantErr: ErrInvalidTwoFactorCode,
+		},
+		"disable with a wrong password": {
+			user: enabled,
+			attempt: func(ctx context.Context, service *TwoFactorService, identity *models.Identity, client models.ClientInfo) error {
+				return service.DisableTwoFactor(ctx, identity, request.TwoFactorDisableRequest{Password: "wrong", Code: "AAAA-BBBB"}, client)
+			},
+			wantErr: ErrPasswordIncorrect,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			useMemoryStores(t)
+			defer func(limit string) { config.LoginMaxAttempts = limit }(config.LoginMaxAttempts)
+			config.LoginMaxAttempts = "1"
+
+			user := tt.user
+			user.ID = 7
+			user.Password = testPasswordHash(t, "Passw0rd!")
+			service := &TwoFactorService{UserRepository: newFakeUserRepository(user), RecoveryCodeRepository: &fakeRecoveryCodeRepository{}}
+
+			ctx := context.Background()
+			identity := &models.Identity{UserID: 7}
+			client := models.ClientInfo{IPAddress: "192.0.2.1"}
+
+			if err := tt.attempt(ctx, service, identity, client); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("first attempt error = %v, want %v", err, tt.wantErr)
+			}
+
+			err := tt.attempt(ctx, service, identity, client)
+			var appError *pkg.AppError
+			if !errors.As(err, &appError) || appError.Code != ErrTooManyLoginAttempts.Code {
+				t.Errorf("attempt on a locked account error = %v, want too many attempts", err)
+			}
+		})
+	}
+}
+
+func TestClearLoginLockoutResolvesAccount(t *testing.T) {
+	useMemoryStores(t)
+	defer func(limit string) { config.LoginMaxAttempts = limit }(config.LoginMaxAttempts)
+	config.LoginMaxAttempts = "1"
+
+	user := models.User{Username: "jane", Email: "jane@example.com"}
+	user.ID = 7
+	service := &AuthService{UserRepository: newFakeUserRepository(user)}
+
+	ctx := context.Background()
+	if _, err := pkg.LoginAttempts.RecordFailure(ctx, pkg.LoginScopeUsername, "user:7"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ClearLoginLockout(ctx, pkg.LoginScopeUsername, "Jane"); err != nil {
+		t.Fatal(err)
+	}
+
+	if lockedFor(t, "user:7") != 0 {
+		t.Error("clearing by username left the account locked")
+	}
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+	"github.com/radenadri/go-boilerplate/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOAuthProviderNotFound    = pkg.NewNotFoundError("oauth_provider_not_found", "Identity provider not found")
+	ErrInvalidOAuthState        = pkg.NewValidationError("oauth_state_invalid", "Invalid or expired OAuth state")
+	ErrOAuthLoginFailed         = pkg.NewUnauthorizedError("oauth_login_failed", "Could not sign in with the identity provider")
+	ErrOAuthProviderUnavailable = pkg.NewUnavailableError("oauth_provider_unavailable", "The identity provider is unavailable, try again later")
+	ErrOAuthEmailUnverified     = pkg.NewForbiddenError("oauth_email_unverified", "The identity provider has not verified this email address")
+	ErrOAuthAccountUnverified   = pkg.NewConflictError("oauth_account_unverified", "An account with this email exists but its email is not verified; sign in with your password and verify it first")
+)
+
+// externalUsernameAttempts bounds the search for a free username when
+// creating an account from an external identity.
+const externalUsernameAttempts = 5
+
+// OAuthService signs users in through external identity providers, linking
+// or creating the local account.
+type OAuthService struct {
+	UserRepository          repositories.UserRepository
+	RoleRepository          repositories.RoleRepository
+	LinkedAccountRepository repositories.LinkedAccountRepository
+	Sessions                *SessionService
+}
+
+func NewOAuthService(userRepository repositories.UserRepository, roleRepository repositories.RoleRepository, linkedAccountRepository repositories.LinkedAccountRepository, sessions *SessionService) *OAuthService {
+	return &OAuthService{
+		UserRepository:          userRepository,
+		RoleRepository:          roleRepository,
+		LinkedAccountRepository: linkedAccountRepository,
+		Sessions:                sessions,
+	}
+}
+
+// StartOAuthLogin begins a login with an external identity provider and
+// returns the URL to send the user to.
+func (s *OAuthService) StartOAuthLogin(ctx context.Context, providerName string) (*response.OAuthAuthorizationResponse, error) {
+	provider, ok := pkg.OAuthProviders[providerName]
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	state, _, err := pkg.GenerateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	authorization, err := pkg.NewOAuthAuthorization(provider.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := provider.AuthorizationURL(ctx, state, authorization)
+	if err != nil {
+		if errors.Is(err, pkg.ErrOAuthProviderUnavailable) {
+			return nil, ErrOAuthProviderUnavailable.Wrap(err)
+		}
+		return nil, err
+	}
+
+	if err := pkg.OAuthStates.Save(ctx, state, authorization, pkg.OAuthStateTTL()); err != nil {
+		return nil, err
+	}
+
+	return &response.OAuthAuthorizationResponse{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+	}, nil
+}
+
+// CompleteOAuthLogin redeems the code the provider sent back and signs the
+// user in. The external account is matched to a user by an earlier link,
+// then by verified email, and otherwise a new user is created. Two-factor
+// authentication still applies.
+func (s *OAuthService) CompleteOAuthLogin(ctx context.Context, providerName string, callbackPayload request.OAuthCallbackRequest, client models.ClientInfo) (*response.UserLoginResponse, error) {
+	provider, ok := pkg.OAuthProviders[providerName]
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+
+	authorization, err := pkg.OAuthStates.Consume(ctx, callbackPayload.State)
+	if err != nil {
+		return nil, err
+	}
+	if authorization == nil || authorization.Provider != provider.Name() {
+		return nil, ErrInvalidOAuthState
+	}
+
+	externalIdentity, err := provider.Exchange(ctx, callbackPayload.Code, *authorization)
+	if err != nil {
+		if errors.Is(err, pkg.ErrOAuthProviderUnavailable) {
+			return nil, ErrOAuthProviderUnavailable.Wrap(err)
+		}
+		return nil, ErrOAuthLoginFailed.Wrap(err)
+	}
+
+	user, err := s.resolveExternalUser(*externalIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Sessions.completeFirstFactor(*user, client)
+}
+
+func (s *OAuthService) resolveExternalUser(externalIdentity pkg.ExternalIdentity) (*models.User, error) {
+	linkedAccount, err := s.LinkedAccountRepository.FindByProviderSubject(externalIdentity.Provider, externalIdentity.Subject)
+	if err == nil {
+		user, err := findUser(s.UserRepository, linkedAccount.UserID)
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrOAuthLoginFailed
+		}
+		return user, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Linking by email is only safe when both sides have proven ownership of
+	// the address; otherwise whoever registered it first could take over the
+	// other account
+	if externalIdentity.Email == "" || !externalIdentity.EmailVerified {
+		return nil, ErrOAuthEmailUnverified
+	}
+
+	user, err := s.UserRepository.FindByEmail(externalIdentity.Email)
+	switch {
+	case err == nil:
+		if user.EmailVerifiedAt == nil {
+			return nil, ErrOAuthAccountUnverified
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = s.createExternalUser(externalIdentity)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	// Without a transaction a failure here leaves the new user unlinked; the
+	// next attempt links it by its verified email
+	if err := s.LinkedAccountRepository.Create(&models.LinkedAccount{
+		UserID:   user.ID,
+		Provider: externalIdentity.Provider,
+		Subject:  externalIdentity.Subject,
+		Email:    utils.NormalizeEmail(externalIdentity.Email),
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createExternalUser registers a user for an external identity. The account
+// gets an unusable random password; the owner can set one through the
+// password reset flow.
+func (s *OAuthService) createExternalUser(externalIdentity pkg.ExternalIdentity) (*models.User, error) {
+	username, err := s.availableUsername(externalIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	randomPassword, _, err := pkg.GenerateSecureToken()
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := pkg.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultRole, err := s.RoleRepository.FindByName(models.RoleUser)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(externalIdentity.Name)
+	if len(name) < 3 {
+		name = username
+	}
+
+	verifiedAt := time.Now()
+	user := models.User{
+		Name:            name,
+		Username:        username,
+		Email:           utils.NormalizeEmail(externalIdentity.Email),
+		Password:        hashedPassword,
+		EmailVerifiedAt: &verifiedAt,
+		Roles:           []models.Role{*defaultRole},
+	}
+
+	if err := s.UserRepository.Create(&user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// availableUsername derives a username from the provider's preferred
+// username or the email's local part, adding a random suffix when taken.
+func (s *OAuthService) availableUsername(externalIdentity pkg.ExternalIdentity) (string, error) {
+	base := sanitizeUsername(externalIdentity.PreferredUsername)
+	if base == "" {
+		localPart, _, _ := strings.Cut(externalIdentity.Email, "@")
+		base = sanitizeUsername(localPart)
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	candidate := base
+	for attempt := 0; attempt < externalUsernameAttempts; attempt++ {
+		_, err := s.UserRepository.FindByUsername(candidate)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		suffix, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s_%04d", base[:min(len(base), 25)], suffix.Int64())
+	}
+
+	return "", pkg.NewConflictError("username_unavailable", "Could not find a free username")
+}
+
+// sanitizeUsername keeps letters, digits, dots, dashes and underscores and
+// caps the length at the 30 characters registration allows.
+func sanitizeUsername(value string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(value) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' {
+			builder.WriteRune(r)
+		}
+		if builder.Len() == 30 {
+			break
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+func newTestOAuthService(linkedAccounts *fakeLinkedAccountRepository, users ...models.User) *OAuthService {
+	return &OAuthService{
+		UserRepository:          newFakeUserRepository(users...),
+		RoleRepository:          &fakeRoleRepository{},
+		LinkedAccountRepository: linkedAccounts,
+	}
+}
+
+func testExternalIdentity() pkg.ExternalIdentity {
+	return pkg.ExternalIdentity{
+		Provider:          "fake",
+		Subject:           "subject-1",
+		Email:             "Jane@Example.com",
+		EmailVerified:     true,
+		Name:              "Jane Doe",
+		PreferredUsername: "jane",
+	}
+}
+
+func TestResolveExternalUserUsesExistingLink(t *testing.T) {
+	verifiedAt := time.Now()
+	user := models.User{Username: "jane", Email: "jane@example.com", EmailVerifiedAt: &verifiedAt}
+	user.ID = 3
+	linkedAccounts := &fakeLinkedAccountRepository{linkedAccounts: []models.LinkedAccount{{ID: 1, UserID: 3, Provider: "fake", Subject: "subject-1"}}}
+	service := newTestOAuthService(linkedAccounts, user)
+
+	// The link wins even when the provider no longer vouches for the email
+	identity := testExternalIdentity()
+	identity.Email = "someone-else@example.com"
+	identity.EmailVerified = false
+
+	resolved, err := service.resolveExternalUser(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.ID != 3 {
+		t.Errorf("resolved user %d, want 3", resolved.ID)
+	}
+	if len(linkedAccounts.linkedAccounts) != 1 {
+		t.Errorf("%d linked accounts, want the existing one only", len(linkedAccounts.linkedAccounts))
+	}
+}
+
+func TestResolveExternalUserLinksByVerifiedEmail(t *testing.T) {
+	verifiedAt := time.Now()
+	user := models.User{Username: "jane", Email: "jane@example.com", EmailVerifiedAt: &verifiedAt}
+	user.ID = 3
+	linkedAccounts := &fakeLinkedAccountRepository{}
+	service := newTestOAuthService(linkedAccounts, user)
+
+	resolved, err := service.resolveExternalUser(testExternalIdentity())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.ID != 3 {
+		t.Errorf("resolved user %d, want 3", resolved.ID)
+	}
+
+	want := models.LinkedAccount{ID: 1, UserID: 3, Provider: "fake", Subject: "subject-1", Email: "jane@example.com"}
+	if len(linkedAccounts.linkedAccounts) != 1 || linkedAccounts.linkedAccounts[0] != want {
+		t.Errorf("linked accounts = %+v, want [%+v]", linkedAccounts.linkedAccounts, want)
+	}
+}
+
+func TestResolveExternalUserRejectsUnverifiedLocalAccount(t *testing.T) {
+	user := models.User{Username: "jane", Email: "jane@example.com"}
+	user.ID = 3
+	linkedAccounts := &fakeLinkedAccountRepository{}
+	service := newTestOAuthService(linkedAccounts, user)
+
+	_, err := service.resolveExternalUser(testExternalIdentity())
+	var appError *pkg.AppError
+	if !errors.Is(err, ErrOAuthAccountUnverified) || !errors.As(err, &appError) || appError.Kind != pkg.ErrorKindConflict {
+		t.Fatalf("resolveExternalUser() error = %v, want conflict %v", err, ErrOAuthAccountUnverified)
+	}
+	if len(linkedAccounts.linkedAccounts) != 0 {
+		t.Errorf("an unverified account was linked: %+v", linkedAccounts.linkedAccounts)
+	}
+}
+
+func TestResolveExternalUserRejectsUnverifiedProviderEmail(t *testing.T) {
+	verifiedAt := time.Now()
+	user := models.User{Username: "jane", Email: "jane@example.com", EmailVerifiedAt: &verifiedAt}
+	user.ID = 3
+	linkedAccounts := &fakeLinkedAccountRepository{}
+	service := newTestOAuthService(linkedAccounts, user)
+
+	identity := testExternalIdentity()
+	identity.EmailVerified = false
+
+	if _, err := service.resolveExternalUser(identity); !errors.Is(err, ErrOAuthEmailUnverified) {
+		t.Fatalf("resolveExternalUser() error = %v, want %v", err, ErrOAuthEmailUnverified)
+	}
+	if len(linkedAccounts.linkedAccounts) != 0 {
+		t.Errorf("an account was linked by an unverified email: %+v", linkedAccounts.linkedAccounts)
+	}
+}
+
+func TestResolveExternalUserCreatesUser(t *testing.T) {
+	// The preferred username is taken, so the new user gets a suffixed one
+	existing := models.User{Username: "jane", Email: "other@example.com"}
+	existing.ID = 1
+	linkedAccounts := &fakeLinkedAccountRepository{}
+	service := newTestOAuthService(linkedAccounts, existing)
+
+	resolved, err := service.resolveExternalUser(testExternalIdentity())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resolved.ID == existing.ID {
+		t.Fatal("resolved the user holding the preferred username")
+	}
+	if resolved.Email != "jane@example.com" || resolved.Name != "Jane Doe" {
+		t.Errorf("created user = %q <%s>, want %q <%s>", resolved.Name, resolved.Email, "Jane Doe", "jane@example.com")
+	}
+	if resolved.Username == "jane" || len(resolved.Username) != len("jane_0000") {
+		t.Errorf("created username = %q, want jane with a numeric suffix", resolved.Username)
+	}
+	if resolved.EmailVerifiedAt == nil {
+		t.Error("created user's email is not marked verified")
+	}
+	if len(resolved.Roles) != 1 || resolved.Roles[0].Name != models.RoleUser {
+		t.Errorf("created user roles = %+v, want %s", resolved.Roles, models.RoleUser)
+	}
+	if resolved.Password == "" {
+		t.Error("created user has no password hash")
+	}
+
+	if len(linkedAccounts.linkedAccounts) != 1 || linkedAccounts.linkedAccounts[0].UserID != resolved.ID {
+		t.Errorf("linked accounts = %+v, want one for user %d", linkedAccounts.linkedAccounts, resolved.ID)
+	}
+}
+
+// failingOAuthProvider fails every code exchange with err.
+type failingOAuthProvider struct {
+	err error
+}
+
+func (p failingOAuthProvider) Name() string {
+	return "fake"
+}
+
+func (p failingOAuthProvider) AuthorizationURL(ctx context.Context, state string, authorization pkg.OAuthAuthorization) (string, error) {
+	return "", p.err
+}
+
+func (p failingOAuthProvider) Exchange(ctx context.Context, code string, authorization pkg.OAuthAuthorization) (*pkg.ExternalIdentity, error) {
+	return nil, p.err
+}
+
+func TestCompleteOAuthLoginReportsProviderOutages(t *testing.T) {
+	previousProviders, previousStates := pkg.OAuthProviders, pkg.OAuthStates
+	t.Cleanup(func() {
+		pkg.OAuthProviders, pkg.OAuthStates = previousProviders, previousStates
+	})
+	pkg.OAuthStates = pkg.NewMemoryOAuthStateStore()
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "provider unreachable", err: fmt.Errorf("%w: connection refused", pkg.ErrOAuthProviderUnavailable), wantErr: ErrOAuthProviderUnavailable},
+		{name: "login rejected", err: pkg.ErrOIDCIDTokenInvalid, wantErr: ErrOAuthLoginFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg.OAuthProviders = map[string]pkg.OAuthProvider{"fake": failingOAuthProvider{err: tt.err}}
+			service := newTestOAuthService(&fakeLinkedAccountRepository{})
+
+			authorization, err := pkg.NewOAuthAuthorization("fake")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := pkg.OAuthStates.Save(context.Background(), "state", authorization, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = service.CompleteOAuthLogin(context.Background(), "fake", request.OAuthCallbackRequest{Code: "code", State: "state"}, models.ClientInfo{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CompleteOAuthLogin() error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, err = service.StartOAuthLogin(context.Background(), "fake")
+			if errors.Is(tt.err, pkg.ErrOAuthProviderUnavailable) && !errors.Is(err, ErrOAuthProviderUnavailable) {
+				t.Errorf("StartOAuthLogin() error = %v, want %v", err, ErrOAuthProviderUnavailable)
+			}
+		})
+	}
+}
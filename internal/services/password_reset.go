@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+	"github.com/radenadri/go-boilerplate/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidResetToken = pkg.NewValidationError("reset_token_invalid", "Invalid or expired password reset token")
+
+	ErrCurrentPasswordIncorrect = pkg.NewValidationError("current_password_incorrect", "Current password is incorrect", response.ValidationError{
+		Field:  "CurrentPassword",
+		Rule:   "current_password",
+		Reason: "Does not match the current password",
+	})
+	ErrPasswordUnchanged = pkg.NewValidationError("password_unchanged", "New password must differ from the current password", response.ValidationError{
+		Field:  "Password",
+		Rule:   "password_unchanged",
+		Reason: "Must differ from the current password",
+	})
+)
+
+// PasswordService resets forgotten passwords and changes known ones. Every
+// new password signs the account out through Sessions.
+type PasswordService struct {
+	UserRepository               repositories.UserRepository
+	PasswordResetTokenRepository repositories.PasswordResetTokenRepository
+	Sessions                     *SessionService
+}
+
+func NewPasswordService(userRepository repositories.UserRepository, passwordResetTokenRepository repositories.PasswordResetTokenRepository, sessions *SessionService) *PasswordService {
+	return &PasswordService{
+		UserRepository:               userRepository,
+		PasswordResetTokenRepository: passwordResetTokenRepository,
+		Sessions:                     sessions,
+	}
+}
+
+// ForgotPassword emails a single-use reset link. The link is issued and sent
+// in the background, so unknown and known addresses answer equally fast and
+// the response never reveals whether an account exists.
+func (s *PasswordService) ForgotPassword(ctx context.Context, forgotPasswordPayload request.ForgotPasswordRequest) error {
+	user, err := s.UserRepository.FindByEmail(forgotPasswordPayload.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	go func() {
+		if err := s.sendPasswordReset(context.WithoutCancel(ctx), *user); err != nil {
+			config.Logger.Error("Failed to send password reset email", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+func (s *PasswordService) sendPasswordReset(ctx context.Context, user models.User) error {
+	// Only the most recent link stays valid
+	if err := s.PasswordResetTokenRepository.InvalidateByUser(user.ID); err != nil {
+		return err
+	}
+
+	token, tokenHash, err := pkg.GenerateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	ttl := passwordResetTTL()
+
+	if err := s.PasswordResetTokenRepository.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return err
+	}
+
+	link, err := tokenLink(config.PasswordResetURL, token)
+	if err != nil {
+		return err
+	}
+
+	return pkg.Mail.Send(ctx, pkg.MailMessage{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password for your account. If it was you, open the link below:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this email.\n",
+			user.Name, link, ttl,
+		),
+	})
+}
+
+// ResetPassword sets a new password using a reset token and signs the user
+// out of every session.
+func (s *PasswordService) ResetPassword(ctx context.Context, resetPasswordPayload request.ResetPasswordRequest) error {
+	resetToken, err := s.PasswordResetTokenRepository.FindByHash(pkg.HashToken(resetPasswordPayload.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	used, err := s.PasswordResetTokenRepository.MarkUsed(resetToken.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidResetToken
+	}
+
+	user, err := findUser(s.UserRepository, resetToken.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+
+	return s.setPassword(ctx, user, resetPasswordPayload.Password)
+}
+
+// ChangePassword replaces the caller's password after checking the current
+// one; wrong guesses count towards the login lockout. Every session is signed
+// out, and a fresh one is started so the caller stays logged in.
+func (s *PasswordService) ChangePassword(ctx context.Context, identity *models.Identity, changePasswordPayload request.ChangePasswordRequest, client models.ClientInfo) (*response.UserLoginResponse, error) {
+	user, err := findUser(s.UserRepository, identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	valid, err := verifyThrottled(ctx, user, client, func() (bool, error) {
+		return pkg.CheckPasswordHash(changePasswordPayload.CurrentPassword, user.Password), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrCurrentPasswordIncorrect
+	}
+
+	if pkg.CheckPasswordHash(changePasswordPayload.Password, user.Password) {
+		return nil, ErrPasswordUnchanged
+	}
+
+	if err := s.setPassword(ctx, user, changePasswordPayload.Password); err != nil {
+		return nil, err
+	}
+
+	return s.Sessions.startSession(*user, client)
+}
+
+// setPassword stores a new password hash and revokes every session, refresh
+// token and access token issued before now.
+func (s *PasswordService) setPassword(ctx context.Context, user *models.User, password string) error {
+	hashedPassword, err := pkg.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	if err := updateUser(s.UserRepository, user, "password"); err != nil {
+		return err
+	}
+
+	return s.Sessions.revokeUserSessions(ctx, user.ID)
+}
+
+func passwordResetTTL() time.Duration {
+	return utils.ParseDurationOrDefault(config.PasswordResetTTL, time.Hour)
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/mapper"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+	"github.com/radenadri/go-boilerplate/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSessionNotFound     = pkg.NewNotFoundError("session_not_found", "Session not found")
+	ErrInvalidRefreshToken = pkg.NewUnauthorizedError("refresh_token_invalid", "Invalid refresh token")
+	ErrRefreshTokenRevoked = pkg.NewUnauthorizedError("refresh_token_revoked", "Refresh token has been revoked")
+	ErrRefreshTokenReused  = pkg.NewUnauthorizedError("refresh_token_reused", "Refresh token reuse detected")
+)
+
+// sessionTouchInterval limits how often last-seen tracking writes to the
+// database for a busy session, like apiKeyTouchInterval.
+const sessionTouchInterval = time.Minute
+
+// SessionService starts, refreshes and ends login sessions and the token
+// pairs issued for them. The other services use it whenever a login
+// completes or an account has to be signed out everywhere.
+type SessionService struct {
+	UserRepository         repositories.UserRepository
+	RefreshTokenRepository repositories.RefreshTokenRepository
+	SessionRepository      repositories.SessionRepository
+}
+
+func NewSessionService(userRepository repositories.UserRepository, refreshTokenRepository repositories.RefreshTokenRepository, sessionRepository repositories.SessionRepository) *SessionService {
+	return &SessionService{
+		UserRepository:         userRepository,
+		RefreshTokenRepository: refreshTokenRepository,
+		SessionRepository:      sessionRepository,
+	}
+}
+
+// Refresh exchanges a refresh token for a new token pair. Every refresh token
+// is single use: presenting one that was already rotated ends its session,
+// logging out both the legitimate client and whoever replayed it.
+func (s *SessionService) Refresh(refreshTokenPayload request.RefreshTokenRequest, client models.ClientInfo) (*response.UserLoginResponse, error) {
+	claims, err := pkg.ParseRefreshToken(refreshTokenPayload.RefreshToken)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	storedToken, err := s.RefreshTokenRepository.FindByID(claims.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if storedToken.FamilyID != claims.Family {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if storedToken.RevokedAt != nil {
+		return nil, ErrRefreshTokenRevoked
+	}
+
+	rotated, err := s.RefreshTokenRepository.MarkRotated(storedToken.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rotated {
+		// The family is the session, so ending it also rejects the access
+		// tokens already issued from it
+		if _, err := s.SessionRepository.Revoke(storedToken.FamilyID, storedToken.UserID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		if err := s.endSession(context.Background(), storedToken.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := findUser(s.UserRepository, storedToken.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if err := s.SessionRepository.Extend(storedToken.FamilyID, client, time.Now().Add(pkg.RefreshTokenTTL())); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(*user, storedToken.FamilyID)
+}
+
+// Logout revokes the caller's access token and session and, when a refresh
+// token owned by the same user is supplied, the refresh family it belongs to.
+func (s *SessionService) Logout(identity *models.Identity, logoutPayload request.LogoutRequest) error {
+	ctx := context.Background()
+
+	if err := pkg.RevokedTokens.Revoke(ctx, identity.TokenID, identity.ExpiresAt); err != nil {
+		return err
+	}
+
+	if identity.SessionID != "" {
+		if _, err := s.SessionRepository.Revoke(identity.SessionID, identity.UserID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := s.endSession(ctx, identity.SessionID); err != nil {
+			return err
+		}
+	}
+
+	if logoutPayload.RefreshToken == "" {
+		return nil
+	}
+
+	claims, err := pkg.ParseRefreshToken(logoutPayload.RefreshToken)
+	if err != nil {
+		return ErrInvalidRefreshToken
+	}
+
+	if claims.Subject != strconv.FormatUint(uint64(identity.UserID), 10) {
+		return ErrInvalidRefreshToken
+	}
+
+	return s.RefreshTokenRepository.RevokeFamily(claims.Family)
+}
+
+// GetSessions lists the caller's active sessions.
+func (s *SessionService) GetSessions(identity *models.Identity) ([]response.SessionResponse, error) {
+	sessions, err := s.SessionRepository.FindActiveByUser(identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapper.ToSessionResponses(sessions, identity.SessionID), nil
+}
+
+// RevokeSession signs one of the caller's sessions out. Sessions belonging to
+// other users are reported as not found.
+func (s *SessionService) RevokeSession(ctx context.Context, identity *models.Identity, sessionID string) error {
+	if _, err := s.SessionRepository.Revoke(sessionID, identity.UserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+
+	return s.endSession(ctx, sessionID)
+}
+
+// TouchSession records that an access token of the session was used from
+// client.
+func (s *SessionService) TouchSession(ctx context.Context, sessionID string, client models.ClientInfo) error {
+	return s.SessionRepository.Touch(sessionID, client, time.Now().Add(-sessionTouchInterval))
+}
+
+// startSession records a new login from client and issues its first token
+// pair. The refresh family doubles as the session ID.
+func (s *SessionService) startSession(user models.User, client models.ClientInfo) (*response.UserLoginResponse, error) {
+	family, err := pkg.GenerateTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.SessionRepository.Create(&models.Session{
+		ID:         family,
+		UserID:     user.ID,
+		Device:     utils.DescribeUserAgent(client.UserAgent),
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(pkg.RefreshTokenTTL()),
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, family)
+}
+
+// endSession revokes the session's refresh family and rejects its access
+// tokens until the longest of them has expired.
+func (s *SessionService) endSession(ctx context.Context, sessionID string) error {
+	if err := s.RefreshTokenRepository.RevokeFamily(sessionID); err != nil {
+		return err
+	}
+
+	return pkg.RevokedTokens.Revoke(ctx, pkg.SessionRevocationID(sessionID), time.Now().Add(pkg.AccessTokenTTL()))
+}
+
+// completeFirstFactor finishes a login whose first factor succeeded: accounts
+// with two-factor authentication get an MFA token, everyone else a session.
+func (s *SessionService) completeFirstFactor(user models.User, client models.ClientInfo) (*response.UserLoginResponse, error) {
+	if user.TwoFactorEnabled() {
+		mfaToken, err := pkg.GenerateMFAPendingToken(user)
+		if err != nil {
+			return nil, err
+		}
+
+		return &response.UserLoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return s.startSession(user, client)
+}
+
+// revokeUserSessions signs the user out everywhere: every session and refresh
+// token is revoked and access tokens issued so far are rejected. It returns
+// once tokens issued from then on are accepted again.
+func (s *SessionService) revokeUserSessions(ctx context.Context, userID uint) error {
+	if err := s.SessionRepository.RevokeByUser(userID); err != nil {
+		return err
+	}
+
+	if err := s.RefreshTokenRepository.RevokeByUser(userID); err != nil {
+		return err
+	}
+
+	// Token iat claims have second precision, so the cutoff is the start of
+	// the next second to also catch tokens issued earlier in this one
+	cutoff := time.Now().Truncate(time.Second).Add(time.Second)
+	if err := pkg.RevokedTokens.RevokeUserTokens(ctx, userID, cutoff); err != nil {
+		return err
+	}
+
+	// Replacement tokens, such as the session ChangePassword starts, must
+	// carry an iat at or after the cutoff
+	time.Sleep(time.Until(cutoff))
+	return nil
+}
+
+func (s *SessionService) issueTokens(user models.User, family string) (*response.UserLoginResponse, error) {
+	tokenPair, err := pkg.GenerateTokenPair(user, family)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.RefreshTokenRepository.Create(&models.RefreshToken{
+		ID:        tokenPair.RefreshTokenID,
+		UserID:    user.ID,
+		FamilyID:  family,
+		ExpiresAt: tokenPair.RefreshExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &response.UserLoginResponse{
+		Token:        tokenPair.AccessToken,
+		RefreshToken: tokenPair.RefreshToken,
+	}, nil
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/http/middlewares"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+)
+
+func newTestSessionService(users ...models.User) (*SessionService, *fakeSessionRepository) {
+	sessionRepository := newFakeSessionRepository()
+
+	return &SessionService{
+		UserRepository:         newFakeUserRepository(users...),
+		RefreshTokenRepository: newFakeRefreshTokenRepository(),
+		SessionRepository:      sessionRepository,
+	}, sessionRepository
+}
+
+// authenticateStatus runs accessToken through AuthenticateJWT and returns the
+// response status.
+func authenticateStatus(t *testing.T, sessions middlewares.SessionToucher, accessToken string) int {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.ErrorHandler())
+	router.GET("/me", middlewares.AuthenticateJWT(sessions), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func TestRefreshReuseEndsSession(t *testing.T) {
+	useMemoryStores(t)
+
+	user := models.User{Username: "jane", Email: "jane@example.com"}
+	user.ID = 7
+	service, sessionRepository := newTestSessionService(user)
+	client := models.ClientInfo{IPAddress: "192.0.2.1"}
+
+	login, err := service.startSession(user, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refreshed, err := service.Refresh(request.RefreshTokenRequest{RefreshToken: login.RefreshToken}, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := authenticateStatus(t, service, refreshed.Token); status != http.StatusNoContent {
+		t.Fatalf("access token before reuse: status = %d, want %d", status, http.StatusNoContent)
+	}
+
+	// Replaying the rotated refresh token ends the whole session
+	_, err = service.Refresh(request.RefreshTokenRequest{RefreshToken: login.RefreshToken}, client)
+	if !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh() with a rotated token error = %v, want %v", err, ErrRefreshTokenReused)
+	}
+
+	for name, accessToken := range map[string]string{"first": login.Token, "refreshed": refreshed.Token} {
+		if status := authenticateStatus(t, service, accessToken); status != http.StatusUnauthorized {
+			t.Errorf("%s access token after reuse: status = %d, want %d", name, status, http.StatusUnauthorized)
+		}
+	}
+
+	sessions, err := sessionRepository.FindActiveByUser(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("active sessions after reuse = %+v, want none", sessions)
+	}
+
+	_, err = service.Refresh(request.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken}, client)
+	if !errors.Is(err, ErrRefreshTokenRevoked) {
+		t.Errorf("Refresh() with the latest token error = %v, want %v", err, ErrRefreshTokenRevoked)
+	}
+}
+
+func TestRevokeUserSessionsRejectsTokensFromTheSameSecond(t *testing.T) {
+	useMemoryStores(t)
+
+	user := models.User{Username: "jane", Email: "jane@example.com"}
+	user.ID = 7
+	service, _ := newTestSessionService(user)
+	client := models.ClientInfo{IPAddress: "192.0.2.1"}
+
+	// Issued in the same second as the revocation below
+	before, err := service.startSession(user, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.revokeUserSessions(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := authenticateStatus(t, service, before.Token); status != http.StatusUnauthorized {
+		t.Errorf("access token issued before revocation: status = %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	after, err := service.startSession(user, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := authenticateStatus(t, service, after.Token); status != http.StatusNoContent {
+		t.Errorf("access token issued after revocation: status = %d, want %d", status, http.StatusNoContent)
+	}
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrTwoFactorAlreadyEnabled = pkg.NewConflictError("two_factor_already_enabled", "Two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled     = pkg.NewConflictError("two_factor_not_enabled", "Two-factor authentication is not enabled")
+	ErrTwoFactorNotEnrolled    = pkg.NewConflictError("two_factor_not_enrolled", "Two-factor enrolment has not been started")
+	ErrInvalidTwoFactorCode    = pkg.NewValidationError("two_factor_code_invalid", "Invalid two-factor code", response.ValidationError{
+		Field:  "Code",
+		Rule:   "two_factor_code",
+		Reason: "Is not a valid code",
+	})
+	ErrPasswordIncorrect = pkg.NewValidationError("password_incorrect", "Password is incorrect", response.ValidationError{
+		Field:  "Password",
+		Rule:   "current_password",
+		Reason: "Does not match the current password",
+	})
+	ErrTwoFactorLoginFailed = pkg.NewUnauthorizedError("two_factor_code_invalid", "Invalid two-factor code")
+	ErrInvalidMFAToken      = pkg.NewUnauthorizedError("mfa_token_invalid", "Invalid or expired MFA token")
+)
+
+// TwoFactorService enrols and disables TOTP two-factor authentication,
+// manages recovery codes and completes logins that need a second factor.
+type TwoFactorService struct {
+	UserRepository         repositories.UserRepository
+	RecoveryCodeRepository repositories.RecoveryCodeRepository
+	Sessions               *SessionService
+}
+
+func NewTwoFactorService(userRepository repositories.UserRepository, recoveryCodeRepository repositories.RecoveryCodeRepository, sessions *SessionService) *TwoFactorService {
+	return &TwoFactorService{
+		UserRepository:         userRepository,
+		RecoveryCodeRepository: recoveryCodeRepository,
+		Sessions:               sessions,
+	}
+}
+
+// EnrollTwoFactor generates a new TOTP secret for the caller. Two-factor
+// authentication only takes effect once a code is confirmed with
+// ConfirmTwoFactor, so an abandoned enrolment never locks the user out.
+func (s *TwoFactorService) EnrollTwoFactor(identity *models.Identity) (*response.TwoFactorEnrollmentResponse, error) {
+	user, err := findUser(s.UserRepository, identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := pkg.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastUsedStep = 0
+	if err := updateUser(s.UserRepository, user, "totp_secret", "totp_last_used_step"); err != nil {
+		return nil, err
+	}
+
+	return &response.TwoFactorEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: pkg.TOTPURI(config.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication once the caller proves
+// their authenticator produces valid codes, and returns the recovery codes.
+// They are only ever shown here and by RegenerateRecoveryCodes.
+func (s *TwoFactorService) ConfirmTwoFactor(ctx context.Context, identity *models.Identity, codePayload request.TwoFactorCodeRequest, client models.ClientInfo) (*response.RecoveryCodesResponse, error) {
+	user, err := findUser(s.UserRepository, identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	valid, err := verifyThrottled(ctx, user, client, func() (bool, error) {
+		return s.verifyTOTP(user, codePayload.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	if err := updateUser(s.UserRepository, user, "totp_enabled_at"); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking the
+// password and a TOTP or recovery code. Failures count towards the login
+// lockout, as in CompleteTwoFactorLogin.
+func (s *TwoFactorService) DisableTwoFactor(ctx context.Context, identity *models.Identity, disablePayload request.TwoFactorDisableRequest, client models.ClientInfo) error {
+	user, err := findUser(s.UserRepository, identity.UserID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	passwordCorrect := true
+	valid, err := verifyThrottled(ctx, user, client, func() (bool, error) {
+		if !pkg.CheckPasswordHash(disablePayload.Password, user.Password) {
+			passwordCorrect = false
+			return false, nil
+		}
+
+		return s.verifySecondFactor(user, disablePayload.Code)
+	})
+	if err != nil {
+		return err
+	}
+	if !passwordCorrect {
+		return ErrPasswordIncorrect
+	}
+	if !valid {
+		return ErrInvalidTwoFactorCode
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastUsedStep = 0
+	if err := updateUser(s.UserRepository, user, "totp_secret", "totp_enabled_at", "totp_last_used_step"); err != nil {
+		return err
+	}
+
+	return s.RecoveryCodeRepository.DeleteByUser(user.ID)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code of the caller. It
+// requires a TOTP code, not a recovery code, and wrong codes count towards
+// the login lockout.
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, identity *models.Identity, codePayload request.TwoFactorCodeRequest, client models.ClientInfo) (*response.RecoveryCodesResponse, error) {
+	user, err := findUser(s.UserRepository, identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TwoFactorEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	valid, err := verifyThrottled(ctx, user, client, func() (bool, error) {
+		return s.verifyTOTP(user, codePayload.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	return s.replaceRecoveryCodes(user.ID)
+}
+
+// CompleteTwoFactorLogin exchanges the MFA token from Login and a TOTP or
+// recovery code for a token pair. Wrong codes count towards the same
+// lockout as wrong passwords.
+func (s *TwoFactorService) CompleteTwoFactorLogin(ctx context.Context, loginPayload request.TwoFactorLoginRequest, client models.ClientInfo) (*response.UserLoginResponse, error) {
+	claims, err := pkg.ParseMFAPendingToken(loginPayload.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	revoked, err := pkg.RevokedTokens.IsRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrInvalidMFAToken
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := findUser(s.UserRepository, uint(userID))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidMFAToken
+		}
+		return nil, err
+	}
+
+	if !user.TwoFactorEnabled() {
+		return nil, ErrInvalidMFAToken
+	}
+
+	valid, err := verifyThrottled(ctx, user, client, func() (bool, error) {
+		return s.verifySecondFactor(user, loginPayload.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrTwoFactorLoginFailed
+	}
+
+	if err := pkg.RevokedTokens.Revoke(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return nil, err
+	}
+
+	return s.Sessions.startSession(*user, client)
+}
+
+// verifyTOTP accepts a code once: the time step it belongs to is recorded
+// and codes from that step or earlier ones are refused afterwards.
+func (s *TwoFactorService) verifyTOTP(user *models.User, code string) (bool, error) {
+	skew, err := strconv.Atoi(config.TOTPSkew)
+	if err != nil || skew < 0 {
+		skew = 1
+	}
+
+	step, valid := pkg.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(code), time.Now(), skew)
+	if !valid {
+		return false, nil
+	}
+
+	fresh, err := s.UserRepository.MarkTOTPStepUsed(user.ID, step)
+	if err != nil {
+		return false, err
+	}
+
+	if fresh {
+		user.TOTPLastUsedStep = step
+	}
+
+	return fresh, nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code.
+func (s *TwoFactorService) verifySecondFactor(user *models.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if isTOTPCode(code) {
+		return s.verifyTOTP(user, code)
+	}
+
+	return s.RecoveryCodeRepository.Consume(user.ID, pkg.HashRecoveryCode(code))
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(userID uint) (*response.RecoveryCodesResponse, error) {
+	codes, err := pkg.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	codeHashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		codeHashes = append(codeHashes, pkg.HashRecoveryCode(code))
+	}
+
+	if err := s.RecoveryCodeRepository.ReplaceForUser(userID, codeHashes); err != nil {
+		return nil, err
+	}
+
+	return &response.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != pkg.TOTPDigits {
+		return false
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+)
+
+// UserPurgeService hard-deletes users once their retention period is over.
+// It is run by the purge job.
+type UserPurgeService struct {
+	UserRepository repositories.UserRepository
+}
+
+func NewUserPurgeService(userRepository repositories.UserRepository) *UserPurgeService {
+	return &UserPurgeService{UserRepository: userRepository}
+}
+
+// PurgeDeletedUsers permanently removes users that have been soft-deleted
+// for longer than the retention period.
+func (s *UserPurgeService) PurgeDeletedUsers(retention time.Duration) (int64, error) {
+	return s.UserRepository.Purge(time.Now().Add(-retention))
+}
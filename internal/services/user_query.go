@@ -0,0 +1,103 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/repositories"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+// NewUserFilter converts list query parameters into a repository filter.
+// Sort keys are checked against repositories.UserSortableColumns and the
+// created_at bounds must be RFC 3339 timestamps; anything rejected is
+// reported as a validation error.
+func NewUserFilter(query request.UserListQuery) (repositories.UserFilter, error) {
+	var validationErrors []response.ValidationError
+
+	filter := repositories.UserFilter{
+		Search:         strings.TrimSpace(query.Search),
+		IncludeDeleted: query.IncludeDeleted,
+	}
+
+	for _, key := range strings.Split(query.Sort, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		field := repositories.SortField{Field: strings.TrimPrefix(key, "-"), Desc: strings.HasPrefix(key, "-")}
+
+		if _, ok := repositories.UserSortableColumns[field.Field]; !ok {
+			validationErrors = append(validationErrors, response.ValidationError{
+				Field:  "sort",
+				Rule:   "sortable",
+				Value:  key,
+				Reason: "Cannot sort by " + field.Field,
+			})
+			continue
+		}
+
+		filter.Sort = append(filter.Sort, field)
+	}
+
+	if len(validationErrors) > 0 {
+		return filter, pkg.NewValidationError("invalid_sort", "Invalid sort field", validationErrors...)
+	}
+
+	createdAfter, err := parseCreatedAtFilter("created_after", query.CreatedAfter)
+	if err != nil {
+		return filter, err
+	}
+	filter.CreatedAfter = createdAfter
+
+	createdBefore, err := parseCreatedAtFilter("created_before", query.CreatedBefore)
+	if err != nil {
+		return filter, err
+	}
+	filter.CreatedBefore = createdBefore
+
+	return filter, nil
+}
+
+// parseCreatedAtFilter reads an optional RFC 3339 timestamp from the named
+// query parameter. A value that does not parse is a validation error rather
+// than a filter that is silently left out.
+func parseCreatedAtFilter(parameter string, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, pkg.NewValidationError("invalid_"+parameter, "Invalid "+parameter+" timestamp", response.ValidationError{
+			Field:  parameter,
+			Rule:   "rfc3339",
+			Value:  value,
+			Reason: "Must be an RFC 3339 timestamp such as 2024-01-02T15:04:05Z",
+		})
+	}
+
+	return &parsed, nil
+}
+
+// CursorSortDirection reports whether keyset pagination should walk
+// newest-first. Keyset mode can only order by created_at, so any other sort
+// key is reported as a validation error.
+func CursorSortDirection(filter repositories.UserFilter) (bool, error) {
+	if len(filter.Sort) == 0 {
+		return true, nil
+	}
+
+	if len(filter.Sort) > 1 || filter.Sort[0].Field != "created_at" {
+		return false, pkg.NewValidationError("invalid_sort", "Invalid sort field", response.ValidationError{
+			Field:  "sort",
+			Rule:   "cursor",
+			Reason: "Cursor pagination can only be sorted by created_at or -created_at",
+		})
+	}
+
+	return filter.Sort[0].Desc, nil
+}
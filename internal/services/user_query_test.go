@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
+	"github.com/radenadri/go-boilerplate/pkg"
+)
+
+func TestNewUserFilterParsesCreatedAtBounds(t *testing.T) {
+	filter, err := NewUserFilter(request.UserListQuery{
+		CreatedAfter:  "2024-01-02T15:04:05Z",
+		CreatedBefore: "2024-02-01T00:00:00+02:00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if filter.CreatedAfter == nil || !filter.CreatedAfter.Equal(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)) {
+		t.Errorf("CreatedAfter = %v, want 2024-01-02T15:04:05Z", filter.CreatedAfter)
+	}
+	if filter.CreatedBefore == nil || !filter.CreatedBefore.Equal(time.Date(2024, 1, 31, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("CreatedBefore = %v, want 2024-01-31T22:00:00Z", filter.CreatedBefore)
+	}
+}
+
+func TestNewUserFilterRejectsInvalidCreatedAtBounds(t *testing.T) {
+	tests := map[string]struct {
+		query    request.UserListQuery
+		wantCode string
+	}{
+		"created_after": {
+			query:    request.UserListQuery{CreatedAfter: "yesterday"},
+			wantCode: "invalid_created_after",
+		},
+		"created_before": {
+			query:    request.UserListQuery{CreatedAfter: "2024-01-02T15:04:05Z", CreatedBefore: "2024-02-01"},
+			wantCode: "invalid_created_before",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewUserFilter(tt.query)
+
+			var appError *pkg.AppError
+			if !errors.As(err, &appError) || appError.Kind != pkg.ErrorKindValidation || appError.Code != tt.wantCode {
+				t.Fatalf("NewUserFilter() error = %v, want validation error %s", err, tt.wantCode)
+			}
+			if len(appError.Fields) != 1 || appError.Fields[0].Field != name {
+				t.Errorf("error fields = %+v, want %s", appError.Fields, name)
+			}
+		})
+	}
+}
@@ -1,128 +1,242 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"math"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/mapper"
 	"github.com/radenadri/go-boilerplate/internal/delivery/dto/request"
 	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
 	"github.com/radenadri/go-boilerplate/internal/domain/models"
 	"github.com/radenadri/go-boilerplate/internal/repositories"
 	"github.com/radenadri/go-boilerplate/pkg"
-	"github.com/radenadri/go-boilerplate/utils"
+	"gorm.io/gorm"
 )
 
+var ErrUserNotFound = pkg.NewNotFoundError("user_not_found", "User not found")
+
+// UserService manages user accounts. Deleting a user goes through Sessions so
+// the account is signed out everywhere.
 type UserService struct {
 	UserRepository repositories.UserRepository
+	RoleRepository repositories.RoleRepository
+	Sessions       *SessionService
 }
 
-func NewUserService(userRepository repositories.UserRepository) *UserService {
-	return &UserService{UserRepository: userRepository}
+func NewUserService(userRepository repositories.UserRepository, roleRepository repositories.RoleRepository, sessions *SessionService) *UserService {
+	return &UserService{
+		UserRepository: userRepository,
+		RoleRepository: roleRepository,
+		Sessions:       sessions,
+	}
 }
 
-func (repository *UserService) GetAllUsers(page int, perPage int) (*response.PaginatedResponse[models.User], error) {
-	// Count total items
-	totalItems, err := repository.UserRepository.Count()
+func (repository *UserService) GetAllUsers(params pkg.PageParams, filter repositories.UserFilter) (*response.PaginatedResponse[response.UserResponse], error) {
+	results, totalItems, err := repository.UserRepository.Paginate(filter, params)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate offset
-	offset := (page - 1) * perPage
+	return pkg.NewPaginatedResponse(mapper.ToUserResponses(results), totalItems, params), nil
+}
+
+type userCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uint      `json:"i"`
+	Desc      bool      `json:"d"`
+}
+
+// GetUsersByCursor pages through users with keyset pagination on
+// (created_at, id). Unlike GetAllUsers it does not use OFFSET, and the total
+// count is only computed when requested.
+func (s *UserService) GetUsersByCursor(cursorQuery request.CursorQuery, filter repositories.UserFilter, desc bool, params pkg.PageParams) (*response.PaginatedResponse[response.UserResponse], error) {
+	var after *repositories.UserKeyset
+
+	if cursorQuery.Cursor != "" {
+		var cursor userCursor
+		if err := pkg.DecodeCursor(cursorQuery.Cursor, &cursor); err != nil || cursor.Desc != desc {
+			return nil, pkg.ErrInvalidCursor
+		}
+		after = &repositories.UserKeyset{CreatedAt: cursor.CreatedAt, ID: cursor.ID}
+	}
 
-	// Retrieve items for current page
-	results, err := repository.UserRepository.FindAll(perPage, offset)
+	// Fetch one extra row to learn whether another page exists
+	results, err := s.UserRepository.FindAfter(filter, after, desc, cursorQuery.Limit+1)
 	if err != nil {
 		return nil, err
 	}
 
-	// Calculate pagination details
-	totalPages := int(math.Ceil(float64(totalItems) / float64(perPage)))
-	hasNextPage := page < totalPages
-	hasPreviousPage := page > 1
-
-	var previousPage *int
-	if hasPreviousPage {
-		prev := page - 1
-		previousPage = &prev
+	hasNextPage := len(results) > cursorQuery.Limit
+	if hasNextPage {
+		results = results[:cursorQuery.Limit]
 	}
 
-	var nextPage int
-	if hasNextPage {
-		nextPage = page + 1
+	totalItems, totalPages := -1, -1
+	if cursorQuery.WithTotal {
+		count, err := s.UserRepository.Count(filter)
+		if err != nil {
+			return nil, err
+		}
+		totalItems = int(count)
+		totalPages = int(math.Ceil(float64(count) / float64(cursorQuery.Limit)))
 	}
 
-	response := &response.PaginatedResponse[models.User]{
+	paginatedResponse := &response.PaginatedResponse[response.UserResponse]{
 		Success: true,
-		Data:    results,
+		Data:    mapper.ToUserResponses(results),
 		Pagination: response.Pagination{
-			TotalItems:      int(totalItems),
+			TotalItems:      totalItems,
 			TotalPages:      totalPages,
-			CurrentPage:     page,
-			ItemsPerPage:    perPage,
+			ItemsPerPage:    cursorQuery.Limit,
 			HasNextPage:     hasNextPage,
-			HasPreviousPage: hasPreviousPage,
-			NextPage:        nextPage,
-			PreviousPage:    previousPage,
+			HasPreviousPage: cursorQuery.Cursor != "",
 		},
 		Links: response.Links{
-			Self:  fmt.Sprintf("/api/items?page=%d&per_page=%d", page, perPage),
-			First: fmt.Sprintf("/api/items?page=1&per_page=%d", perPage),
-			Last:  fmt.Sprintf("/api/items?page=%d&per_page=%d", totalPages, perPage),
-			Next:  fmt.Sprintf("/api/items?page=%d&per_page=%d", nextPage, perPage),
+			Self:  cursorLink(params, cursorQuery, cursorQuery.Cursor),
+			First: cursorLink(params, cursorQuery, ""),
 		},
 	}
 
-	return response, nil
+	if hasNextPage {
+		last := results[len(results)-1]
+		nextCursor, err := pkg.EncodeCursor(userCursor{CreatedAt: last.CreatedAt, ID: last.ID, Desc: desc})
+		if err != nil {
+			return nil, err
+		}
+		paginatedResponse.NextCursor = &nextCursor
+		paginatedResponse.Links.Next = cursorLink(params, cursorQuery, nextCursor)
+	}
+
+	return paginatedResponse, nil
 }
 
-func (s *UserService) Register(userPayload models.User) (*response.UserResponse, error) {
-	hashedPassword, err := pkg.HashPassword(userPayload.Password)
+func cursorLink(params pkg.PageParams, cursorQuery request.CursorQuery, cursor string) string {
+	values := url.Values{
+		"cursor": {cursor},
+		"limit":  {strconv.Itoa(cursorQuery.Limit)},
+	}
+
+	if cursorQuery.WithTotal {
+		values.Set("with_total", "true")
+	}
+
+	return params.Link(values)
+}
+
+func (s *UserService) Register(userRegisterPayload request.UserRegisterRequest) (*response.UserResponse, error) {
+	hashedPassword, err := pkg.HashPassword(userRegisterPayload.Password)
 	if err != nil {
 		return nil, err
 	}
 
-	userData := models.User{
-		Name:     userPayload.Name,
-		Username: userPayload.Username,
-		Email:    userPayload.Email,
-		Password: hashedPassword,
+	userData := mapper.UserFromRegisterRequest(userRegisterPayload, hashedPassword)
+
+	defaultRole, err := s.RoleRepository.FindByName(models.RoleUser)
+	if err != nil {
+		return nil, err
 	}
+	userData.Roles = []models.Role{*defaultRole}
 
 	if err := s.UserRepository.Create(&userData); err != nil {
 		return nil, err
 	}
 
-	userResponse := &response.UserResponse{
-		ID:        userData.ID,
-		Name:      userData.Name,
-		Username:  userData.Username,
-		Email:     userData.Email,
-		CreatedAt: utils.ToTimestamp(userData.CreatedAt),
-	}
+	notifyEmailVerification(context.Background(), userData)
 
-	return userResponse, nil
+	return mapper.ToUserResponse(userData), nil
 }
 
-func (s *UserService) Login(userLoginPayload request.UserLoginRequest) (*response.UserLoginResponse, error) {
-	user, err := s.UserRepository.FindByUsername(userLoginPayload.Username)
+func (s *UserService) GetUserByID(id uint) (*response.UserResponse, error) {
+	user, err := findUser(s.UserRepository, id)
+	if err != nil {
+		return nil, err
+	}
 
+	return mapper.ToUserResponse(*user), nil
+}
+
+func (s *UserService) UpdateUser(id uint, userUpdatePayload request.UserUpdateRequest) (*response.UserResponse, error) {
+	user, err := findUser(s.UserRepository, id)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, err
 	}
 
-	if !pkg.CheckPasswordHash(userLoginPayload.Password, user.Password) {
-		return nil, errors.New("invalid password")
+	emailChanged := mapper.ApplyUserUpdate(user, userUpdatePayload)
+
+	if err := updateUser(s.UserRepository, user, "name", "username", "email", "email_verified_at"); err != nil {
+		return nil, err
+	}
+
+	if emailChanged {
+		notifyEmailVerification(context.Background(), *user)
+	}
+
+	return mapper.ToUserResponse(*user), nil
+}
+
+// DeleteUser removes the user and revokes their sessions, refresh tokens and
+// every access token issued so far, so the account is locked out at once.
+func (s *UserService) DeleteUser(ctx context.Context, id uint) error {
+	if err := s.UserRepository.Delete(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return s.Sessions.revokeUserSessions(ctx, id)
+}
+
+func (s *UserService) RestoreUser(id uint) (*response.UserResponse, error) {
+	if err := s.UserRepository.Restore(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
 	}
 
-	token, err := pkg.GenerateJWT(*user)
+	return s.GetUserByID(id)
+}
 
+func findUser(userRepository repositories.UserRepository, id uint) (*models.User, error) {
+	user, err := userRepository.FindByID(id)
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 
-	return &response.UserLoginResponse{
-		Token: token,
-	}, nil
+	return user, nil
+}
+
+// updateUser writes the given columns of user and reports a user deleted in
+// the meantime as ErrUserNotFound.
+func updateUser(userRepository repositories.UserRepository, user *models.User, columns ...string) error {
+	if err := userRepository.Update(user, columns...); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// tokenLink appends token as a query parameter to a frontend URL.
+func tokenLink(baseURL string, token string) (string, error) {
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
 }
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
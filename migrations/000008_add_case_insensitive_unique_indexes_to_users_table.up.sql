@@ -0,0 +1,7 @@
+-- Emails are stored lowercased from now on; usernames keep their case but
+-- must be unique regardless of it. This fails if existing rows only differ by
+-- case, which has to be resolved by hand first.
+UPDATE users SET email = LOWER(TRIM(email)), username = TRIM(username);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username));
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email));
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS linked_accounts (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_linked_accounts_provider_subject ON linked_accounts (provider, subject);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_linked_accounts_user_id_provider ON linked_accounts (user_id, provider);
@@ -0,0 +1,12 @@
+-- Soft-deleted users keep their row until they are purged, so uniqueness only
+-- applies among live users: a deleted account's username and email can be
+-- registered again right away. Restoring the deleted account then fails with
+-- a conflict until the new owner gives them up.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+
+DROP INDEX IF EXISTS idx_users_username_lower;
+DROP INDEX IF EXISTS idx_users_email_lower;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username)) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,20 @@
+package pkg
+
+// apiKeyPrefix marks API keys so they are recognisable in logs and by
+// secret scanners.
+const apiKeyPrefix = "gbk_"
+
+// apiKeyDisplayLength is how much of the key is kept in clear for display.
+const apiKeyDisplayLength = len(apiKeyPrefix) + 8
+
+// GenerateAPIKey returns a new API key, the prefix to show to its owner and
+// the hash to store.
+func GenerateAPIKey() (key string, prefix string, hash string, err error) {
+	token, _, err := GenerateSecureToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	key = apiKeyPrefix + token
+	return key, key[:apiKeyDisplayLength], HashToken(key), nil
+}
@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/radenadri/go-boilerplate/config"
+)
+
+var ErrInvalidCursor = NewValidationError("invalid_cursor", "Invalid cursor")
+
+// InitCursorSigning refuses to start without a dedicated CURSOR_SECRET. An
+// empty key would let anyone forge cursors, and sharing JWT_SECRET would use
+// one key for two purposes.
+func InitCursorSigning() error {
+	if config.CursorSecret == "" {
+		return errors.New("CURSOR_SECRET is required")
+	}
+
+	if config.CursorSecret == config.JWTSecret {
+		return errors.New("CURSOR_SECRET must differ from JWT_SECRET")
+	}
+
+	return nil
+}
+
+// EncodeCursor serializes a pagination position into an opaque token. The
+// payload is signed so clients cannot forge positions.
+func EncodeCursor(position interface{}) (string, error) {
+	payload, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	return encodedPayload + "." + signCursor(encodedPayload), nil
+}
+
+// DecodeCursor verifies a token produced by EncodeCursor and unmarshals its
+// position into target.
+func DecodeCursor(cursor string, target interface{}) error {
+	encodedPayload, signature, found := strings.Cut(cursor, ".")
+	if !found {
+		return ErrInvalidCursor
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(signCursor(encodedPayload))) {
+		return ErrInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, target); err != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
+
+func signCursor(encodedPayload string) string {
+	mac := hmac.New(sha256.New, []byte(config.CursorSecret))
+	mac.Write([]byte(encodedPayload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/radenadri/go-boilerplate/config"
+)
+
+func TestInitCursorSigning(t *testing.T) {
+	defer func(secret, jwtSecret string) {
+		config.CursorSecret, config.JWTSecret = secret, jwtSecret
+	}(config.CursorSecret, config.JWTSecret)
+
+	config.JWTSecret = "jwt-secret"
+
+	for secret, wantErr := range map[string]bool{"": true, "jwt-secret": true, "cursor-secret": false} {
+		config.CursorSecret = secret
+		if err := InitCursorSigning(); (err != nil) != wantErr {
+			t.Errorf("InitCursorSigning() with %q: err = %v, want error %v", secret, err, wantErr)
+		}
+	}
+}
+
+func TestCursorRoundTripAndTampering(t *testing.T) {
+	defer func(secret string) { config.CursorSecret = secret }(config.CursorSecret)
+	config.CursorSecret = "cursor-secret"
+
+	type position struct {
+		ID uint `json:"i"`
+	}
+
+	cursor, err := EncodeCursor(position{ID: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded position
+	if err := DecodeCursor(cursor, &decoded); err != nil || decoded.ID != 42 {
+		t.Fatalf("DecodeCursor() = %+v, %v", decoded, err)
+	}
+
+	forged, _ := EncodeCursor(position{ID: 1})
+	config.CursorSecret = "another-secret"
+	if err := DecodeCursor(forged, &decoded); err == nil {
+		t.Error("DecodeCursor() accepted a cursor signed with another key")
+	}
+}
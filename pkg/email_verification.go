@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/utils"
+)
+
+var ErrInvalidEmailVerificationToken = errors.New("invalid email verification token")
+
+// EmailVerificationClaims ties a verification link to the address it was
+// sent to, so changing the email invalidates links sent to the old one.
+type EmailVerificationClaims struct {
+	Type  string `json:"typ"`
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+func GenerateEmailVerificationToken(user models.User) (string, error) {
+	tokenID, err := GenerateTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := &EmailVerificationClaims{
+		Type:             TokenTypeEmailVerification,
+		Email:            user.Email,
+		RegisteredClaims: registeredClaims(tokenID, user.ID, now, now.Add(EmailVerificationTTL())),
+	}
+	return signToken(claims)
+}
+
+func ParseEmailVerificationToken(tokenString string) (*EmailVerificationClaims, error) {
+	claims := &EmailVerificationClaims{}
+
+	if err := parseToken(tokenString, claims); err != nil {
+		return nil, ErrInvalidEmailVerificationToken
+	}
+
+	if claims.Type != TokenTypeEmailVerification || claims.ID == "" || claims.Email == "" {
+		return nil, ErrInvalidEmailVerificationToken
+	}
+
+	return claims, nil
+}
+
+func EmailVerificationTTL() time.Duration {
+	return utils.ParseDurationOrDefault(config.EmailVerificationTTL, 24*time.Hour)
+}
@@ -0,0 +1,107 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+)
+
+type ErrorKind string
+
+const (
+	ErrorKindValidation      ErrorKind = "validation"
+	ErrorKindUnauthorized    ErrorKind = "unauthorized"
+	ErrorKindForbidden       ErrorKind = "forbidden"
+	ErrorKindNotFound        ErrorKind = "not_found"
+	ErrorKindConflict        ErrorKind = "conflict"
+	ErrorKindTooManyRequests ErrorKind = "too_many_requests"
+	ErrorKindInternal        ErrorKind = "internal"
+	ErrorKindUnavailable     ErrorKind = "unavailable"
+)
+
+// AppError is an error that is safe to show to API clients. Kind decides the
+// HTTP status, Code is a stable machine-readable identifier and Message is the
+// human-readable text. The wrapped Err is only ever logged. RetryAfter, when
+// set, is sent back as a Retry-After header.
+type AppError struct {
+	Kind       ErrorKind
+	Code       string
+	Message    string
+	Fields     []response.ValidationError
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches AppErrors by Kind and Code so sentinel values can be compared
+// with errors.Is.
+func (e *AppError) Is(target error) bool {
+	var appError *AppError
+	if !errors.As(target, &appError) {
+		return false
+	}
+
+	return e.Kind == appError.Kind && e.Code == appError.Code
+}
+
+// Wrap returns a copy of the error that records the underlying cause.
+func (e *AppError) Wrap(err error) *AppError {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+func NewValidationError(code string, message string, fields ...response.ValidationError) *AppError {
+	return &AppError{Kind: ErrorKindValidation, Code: code, Message: message, Fields: fields}
+}
+
+func NewUnauthorizedError(code string, message string) *AppError {
+	return &AppError{Kind: ErrorKindUnauthorized, Code: code, Message: message}
+}
+
+func NewForbiddenError(code string, message string) *AppError {
+	return &AppError{Kind: ErrorKindForbidden, Code: code, Message: message}
+}
+
+func NewNotFoundError(code string, message string) *AppError {
+	return &AppError{Kind: ErrorKindNotFound, Code: code, Message: message}
+}
+
+func NewConflictError(code string, message string, fields ...response.ValidationError) *AppError {
+	return &AppError{Kind: ErrorKindConflict, Code: code, Message: message, Fields: fields}
+}
+
+func NewTooManyRequestsError(code string, message string, retryAfter time.Duration) *AppError {
+	return &AppError{Kind: ErrorKindTooManyRequests, Code: code, Message: message, RetryAfter: retryAfter}
+}
+
+func NewUnavailableError(code string, message string) *AppError {
+	return &AppError{Kind: ErrorKindUnavailable, Code: code, Message: message}
+}
+
+func NewInternalError(err error) *AppError {
+	return &AppError{Kind: ErrorKindInternal, Code: "internal_error", Message: "Internal Server Error", Err: err}
+}
+
+// AsAppError returns err as an AppError, treating anything untyped as an
+// internal error so its message never reaches the client.
+func AsAppError(err error) *AppError {
+	var appError *AppError
+	if errors.As(err, &appError) {
+		return appError
+	}
+
+	return NewInternalError(err)
+}
@@ -1,20 +1,272 @@
 package pkg
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/radenadri/go-boilerplate/config"
 	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/utils"
 )
 
-func GenerateJWT(user models.User) (string, error) {
-	claims := &jwt.MapClaims{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-		"exp":   time.Now().Add(time.Hour * 72).Unix(),
+const (
+	TokenTypeAccess            = "access"
+	TokenTypeRefresh           = "refresh"
+	TokenTypeEmailVerification = "email_verification"
+	TokenTypeMFAPending        = "mfa_pending"
+)
+
+var (
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenExpired          = errors.New("token is expired")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenInvalid          = errors.New("token is invalid")
+)
+
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	RefreshTokenID   string
+	RefreshExpiresAt time.Time
+}
+
+type RefreshClaims struct {
+	Type   string `json:"typ"`
+	Family string `json:"fam"`
+	jwt.RegisteredClaims
+}
+
+type AccessClaims struct {
+	UserID      uint     `json:"id"`
+	Name        string   `json:"name"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	SessionID   string   `json:"sid,omitempty"`
+	Type        string   `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func GenerateJWT(user models.User, sessionID string) (string, error) {
+	tokenID, err := GenerateTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := &AccessClaims{
+		UserID:           user.ID,
+		Name:             user.Name,
+		Username:         user.Username,
+		Email:            user.Email,
+		Roles:            user.RoleNames(),
+		Permissions:      user.PermissionNames(),
+		SessionID:        sessionID,
+		Type:             TokenTypeAccess,
+		RegisteredClaims: registeredClaims(tokenID, user.ID, now, now.Add(AccessTokenTTL())),
+	}
+	return signToken(claims)
+}
+
+// ParseAccessToken verifies an access token and returns its claims. Errors
+// are one of ErrTokenMalformed, ErrTokenExpired, ErrTokenSignatureInvalid or
+// ErrTokenInvalid so callers can report why a token was refused.
+func ParseAccessToken(tokenString string) (*AccessClaims, error) {
+	claims := &AccessClaims{}
+
+	if err := parseToken(tokenString, claims); err != nil {
+		return nil, err
+	}
+
+	// Refresh tokens are signed with the same key but must only be
+	// accepted by the /refresh endpoint.
+	if claims.Type != TokenTypeAccess || claims.ID == "" {
+		return nil, ErrTokenInvalid
+	}
+
+	return claims, nil
+}
+
+// Identity converts verified access token claims into the caller identity
+// that is shared with controllers and services.
+func (claims *AccessClaims) Identity() *models.Identity {
+	return &models.Identity{
+		UserID:      claims.UserID,
+		Username:    claims.Username,
+		Email:       claims.Email,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+		TokenID:     claims.ID,
+		SessionID:   claims.SessionID,
+		ExpiresAt:   claims.ExpiresAt.Time,
+	}
+}
+
+// GenerateTokenPair issues an access token together with a refresh token
+// belonging to the given rotation family. The family identifies the login
+// session, so it is also the access token's session ID.
+func GenerateTokenPair(user models.User, family string) (*TokenPair, error) {
+	accessToken, err := GenerateJWT(user, family)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID, err := GenerateTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(RefreshTokenTTL())
+
+	claims := &RefreshClaims{
+		Type:             TokenTypeRefresh,
+		Family:           family,
+		RegisteredClaims: registeredClaims(tokenID, user.ID, now, expiresAt),
+	}
+
+	refreshToken, err := signToken(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		RefreshTokenID:   tokenID,
+		RefreshExpiresAt: expiresAt,
+	}, nil
+}
+
+func ParseRefreshToken(tokenString string) (*RefreshClaims, error) {
+	claims := &RefreshClaims{}
+
+	if err := parseToken(tokenString, claims); err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	if claims.Type != TokenTypeRefresh || claims.ID == "" || claims.Family == "" {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	return claims, nil
+}
+
+func registeredClaims(tokenID string, userID uint, issuedAt time.Time, expiresAt time.Time) jwt.RegisteredClaims {
+	claims := jwt.RegisteredClaims{
+		ID:        tokenID,
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		IssuedAt:  jwt.NewNumericDate(issuedAt),
+		NotBefore: jwt.NewNumericDate(issuedAt),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	if config.JWTIssuer != "" {
+		claims.Issuer = config.JWTIssuer
+	}
+
+	if config.JWTAudience != "" {
+		claims.Audience = jwt.ClaimStrings{config.JWTAudience}
+	}
+
+	return claims
+}
+
+func signToken(claims jwt.Claims) (string, error) {
+	if Keys == nil {
+		return "", errors.New("JWT signing keys are not initialized")
+	}
+
+	return Keys.Sign(claims)
+}
+
+func parseToken(tokenString string, claims jwt.Claims) error {
+	if Keys == nil {
+		return ErrTokenSignatureInvalid
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, Keys.Keyfunc, parserOptions()...)
+
+	switch {
+	case err == nil && token.Valid:
+		return nil
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrTokenSignatureInvalid
+	default:
+		return ErrTokenInvalid
+	}
+}
+
+func parserOptions() []jwt.ParserOption {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(AllowedSigningAlgorithms()),
+		jwt.WithLeeway(utils.ParseDurationOrDefault(config.JWTLeeway, 0)),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+
+	if config.JWTIssuer != "" {
+		options = append(options, jwt.WithIssuer(config.JWTIssuer))
+	}
+
+	if config.JWTAudience != "" {
+		options = append(options, jwt.WithAudience(config.JWTAudience))
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.JWTSecret))
+
+	return options
+}
+
+// AllowedSigningAlgorithms returns the configured allow-list of "alg" header
+// values, defaulting to the algorithms of the loaded keys. Tokens signed with
+// any other algorithm, including "none", are rejected before their signature
+// is checked.
+func AllowedSigningAlgorithms() []string {
+	var algorithms []string
+
+	for _, algorithm := range strings.Split(config.JWTAlgorithms, ",") {
+		algorithm = strings.TrimSpace(algorithm)
+		if algorithm != "" && algorithm != "none" {
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+
+	if len(algorithms) == 0 && Keys != nil {
+		return Keys.Algorithms()
+	}
+
+	return algorithms
+}
+
+// SessionRevocationID is the revocation store entry that invalidates every
+// access token of a session.
+func SessionRevocationID(sessionID string) string {
+	return "session:" + sessionID
+}
+
+func GenerateTokenID() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+func AccessTokenTTL() time.Duration {
+	return utils.ParseDurationOrDefault(config.JWTAccessTokenTTL, 15*time.Minute)
+}
+
+func RefreshTokenTTL() time.Duration {
+	return utils.ParseDurationOrDefault(config.JWTRefreshTokenTTL, 30*24*time.Hour)
 }
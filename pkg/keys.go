@@ -0,0 +1,321 @@
+package pkg
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/radenadri/go-boilerplate/config"
+)
+
+// KeySet holds the key used to sign new tokens and every key that is still
+// accepted when verifying them. Verification keys are indexed by "kid" so a
+// new signing key can be rolled out while tokens signed with the previous one
+// remain valid until they expire.
+type KeySet struct {
+	SigningKeyID     string
+	SigningMethod    jwt.SigningMethod
+	SigningKey       interface{}
+	VerificationKeys map[string]VerificationKey
+}
+
+type VerificationKey struct {
+	Method jwt.SigningMethod
+	Key    interface{}
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var Keys *KeySet
+
+func InitSigningKeys() error {
+	keySet, err := LoadKeySet()
+	if err != nil {
+		return err
+	}
+
+	Keys = keySet
+	return nil
+}
+
+// LoadKeySet builds the key set from configuration. HS256 uses JWT_SECRET;
+// RS256 and EdDSA read a PEM private key from JWT_PRIVATE_KEY_PATH and any
+// retired public keys listed in JWT_VERIFICATION_KEYS as "kid=path" or
+// "kid=alg:path" pairs.
+func LoadKeySet() (*KeySet, error) {
+	method := jwt.GetSigningMethod(config.JWTSigningMethod)
+	if method == nil || method.Alg() == "none" {
+		return nil, fmt.Errorf("unsupported JWT signing method %q", config.JWTSigningMethod)
+	}
+
+	keySet := &KeySet{
+		SigningKeyID:     config.JWTKeyID,
+		SigningMethod:    method,
+		VerificationKeys: make(map[string]VerificationKey),
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if config.JWTSecret == "" {
+			return nil, errors.New("JWT_SECRET is required for HMAC signing")
+		}
+		keySet.SigningKey = []byte(config.JWTSecret)
+		keySet.VerificationKeys[keySet.SigningKeyID] = VerificationKey{Method: method, Key: keySet.SigningKey}
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+		privateKey, publicKey, err := loadPrivateKey(method, config.JWTPrivateKeyPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if keySet.SigningKeyID == "" {
+			keySet.SigningKeyID, err = keyThumbprint(publicKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		keySet.SigningKey = privateKey
+		keySet.VerificationKeys[keySet.SigningKeyID] = VerificationKey{Method: method, Key: publicKey}
+	default:
+		return nil, fmt.Errorf("unsupported JWT signing method %q", config.JWTSigningMethod)
+	}
+
+	for _, entry := range strings.Split(config.JWTVerificationKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		keyID, path, found := strings.Cut(entry, "=")
+		if !found || keyID == "" || path == "" {
+			return nil, fmt.Errorf("invalid JWT verification key entry %q, expected kid=path or kid=alg:path", entry)
+		}
+
+		// Without an explicit algorithm a retired key is assumed to have been
+		// used with the configured signing method. Paths can contain colons
+		// too, so the part before one is only an algorithm when it names one.
+		keyMethod := method
+		explicitAlg := false
+		if alg, keyPath, found := strings.Cut(path, ":"); found {
+			if alg == "none" {
+				return nil, fmt.Errorf("unsupported algorithm %q in JWT verification key entry %q", alg, entry)
+			}
+			if algMethod := jwt.GetSigningMethod(alg); algMethod != nil {
+				keyMethod, path, explicitAlg = algMethod, keyPath, true
+			}
+		}
+
+		verificationKey, err := loadPublicKey(keyMethod, path)
+		if err != nil {
+			return nil, err
+		}
+		if explicitAlg && verificationKey.Method.Alg() != keyMethod.Alg() {
+			return nil, fmt.Errorf("key in %s cannot be used with %s", path, keyMethod.Alg())
+		}
+
+		keySet.VerificationKeys[keyID] = verificationKey
+	}
+
+	return keySet, nil
+}
+
+// Sign signs the claims with the active signing key and stamps its "kid".
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.SigningMethod, claims)
+	if k.SigningKeyID != "" {
+		token.Header["kid"] = k.SigningKeyID
+	}
+
+	return token.SignedString(k.SigningKey)
+}
+
+// Keyfunc resolves the verification key from the token "kid" header. The key
+// is only returned when the token "alg" matches the algorithm the key was
+// registered for, which rules out algorithm confusion attacks.
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	verificationKey, ok := k.VerificationKeys[keyID]
+	if !ok {
+		return nil, ErrTokenSignatureInvalid
+	}
+
+	if token.Method.Alg() != verificationKey.Method.Alg() {
+		return nil, ErrTokenSignatureInvalid
+	}
+
+	return verificationKey.Key, nil
+}
+
+// Algorithms lists the algorithms of every verification key.
+func (k *KeySet) Algorithms() []string {
+	seen := make(map[string]bool)
+	var algorithms []string
+
+	for _, verificationKey := range k.VerificationKeys {
+		algorithm := verificationKey.Method.Alg()
+		if !seen[algorithm] {
+			seen[algorithm] = true
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+
+	sort.Strings(algorithms)
+	return algorithms
+}
+
+// JWKS publishes the public verification keys. Shared HMAC secrets are never
+// included.
+func (k *KeySet) JWKS() JWKSet {
+	jwks := JWKSet{Keys: []JWK{}}
+
+	keyIDs := make([]string, 0, len(k.VerificationKeys))
+	for keyID := range k.VerificationKeys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+
+	for _, keyID := range keyIDs {
+		verificationKey := k.VerificationKeys[keyID]
+
+		switch key := verificationKey.Key.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "RSA",
+				Use:       "sig",
+				KeyID:     keyID,
+				Algorithm: verificationKey.Method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				KeyType:   "OKP",
+				Use:       "sig",
+				KeyID:     keyID,
+				Algorithm: verificationKey.Method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+
+	return jwks
+}
+
+func loadPrivateKey(method jwt.SigningMethod, path string) (crypto.PrivateKey, crypto.PublicKey, error) {
+	if path == "" {
+		return nil, nil, errors.New("JWT_PRIVATE_KEY_PATH is required for asymmetric signing")
+	}
+
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch method.(type) {
+	case *jwt.SigningMethodRSA:
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return privateKey, &privateKey.PublicKey, nil
+	default:
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		edPrivateKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, nil, errors.New("JWT private key is not an Ed25519 key")
+		}
+		return edPrivateKey, edPrivateKey.Public(), nil
+	}
+}
+
+// loadPublicKey reads a PEM public key for method. When the key type does not
+// fit method, RSA keys fall back to RS256 and Ed25519 keys to EdDSA, since
+// each of those has only one algorithm to choose from.
+func loadPublicKey(method jwt.SigningMethod, path string) (VerificationKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return VerificationKey{}, err
+	}
+
+	if rsaPublicKey, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		switch method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			return VerificationKey{Method: method, Key: rsaPublicKey}, nil
+		}
+		return VerificationKey{Method: jwt.SigningMethodRS256, Key: rsaPublicKey}, nil
+	}
+
+	if edPublicKey, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		return VerificationKey{Method: jwt.SigningMethodEdDSA, Key: edPublicKey}, nil
+	}
+
+	return VerificationKey{}, fmt.Errorf("unsupported public key in %s", path)
+}
+
+// keyThumbprint derives a stable "kid" from the public key (RFC 7638).
+func keyThumbprint(publicKey crypto.PublicKey) (string, error) {
+	var members interface{}
+
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		}
+	case ed25519.PublicKey:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{
+			Crv: "Ed25519",
+			Kty: "OKP",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}
+	default:
+		return "", errors.New("unsupported public key type")
+	}
+
+	encoded, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(encoded)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
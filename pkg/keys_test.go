@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/radenadri/go-boilerplate/config"
+)
+
+func writeRSAKeys(t *testing.T, dir, name string) (privatePath, publicPath string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privatePath = filepath.Join(dir, name+".pem")
+	publicPath = filepath.Join(dir, name+".pub.pem")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	if err := os.WriteFile(privatePath, privatePEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return privatePath, publicPath
+}
+
+func TestLoadKeySetVerificationKeyAlgorithms(t *testing.T) {
+	defer func(method, keyID, privatePath, verificationKeys string) {
+		config.JWTSigningMethod, config.JWTKeyID = method, keyID
+		config.JWTPrivateKeyPath, config.JWTVerificationKeys = privatePath, verificationKeys
+	}(config.JWTSigningMethod, config.JWTKeyID, config.JWTPrivateKeyPath, config.JWTVerificationKeys)
+
+	dir := t.TempDir()
+	privatePath, _ := writeRSAKeys(t, dir, "current")
+	_, retiredPath := writeRSAKeys(t, dir, "retired")
+
+	colonDir := filepath.Join(dir, "secrets:v2")
+	if err := os.Mkdir(colonDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	_, colonPath := writeRSAKeys(t, colonDir, "retired")
+
+	config.JWTSigningMethod = "RS512"
+	config.JWTKeyID = "current"
+	config.JWTPrivateKeyPath = privatePath
+
+	tests := []struct {
+		name    string
+		entry   string
+		wantAlg string
+		wantErr bool
+	}{
+		{name: "defaults to the signing method", entry: "old=" + retiredPath, wantAlg: "RS512"},
+		{name: "explicit algorithm", entry: "old=RS256:" + retiredPath, wantAlg: "RS256"},
+		{name: "algorithm not matching the key", entry: "old=EdDSA:" + retiredPath, wantErr: true},
+		{name: "colon in the path", entry: "old=" + colonPath, wantAlg: "RS512"},
+		{name: "colon in the path after an algorithm", entry: "old=RS256:" + colonPath, wantAlg: "RS256"},
+		{name: "unknown algorithm", entry: "old=XS256:" + retiredPath, wantErr: true},
+		{name: "none algorithm", entry: "old=none:" + retiredPath, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.JWTVerificationKeys = tt.entry
+
+			keySet, err := LoadKeySet()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadKeySet() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKeySet() error = %v", err)
+			}
+			if got := keySet.VerificationKeys["old"].Method.Alg(); got != tt.wantAlg {
+				t.Errorf("retired key algorithm = %s, want %s", got, tt.wantAlg)
+			}
+		})
+	}
+}
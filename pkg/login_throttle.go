@@ -0,0 +1,292 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	LoginScopeUsername = "username"
+	LoginScopeIP       = "ip"
+)
+
+// LoginLockout describes a login subject (an account, an unknown identifier or
+// a client IP) that is temporarily refused.
+type LoginLockout struct {
+	Scope       string    `json:"scope"`
+	Subject     string    `json:"subject"`
+	Failures    int64     `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+// LoginThrottle counts failed logins per scope and subject. Once a subject
+// reaches its limit inside the failure window it is locked out, and every
+// further failure doubles the lockout up to LOGIN_LOCKOUT_MAX.
+type LoginThrottle interface {
+	// LockedFor returns how long the subject is still locked out, or zero.
+	LockedFor(ctx context.Context, scope string, subject string) (time.Duration, error)
+	// RecordFailure counts a failed attempt and returns the lockout it
+	// triggered, or zero.
+	RecordFailure(ctx context.Context, scope string, subject string) (time.Duration, error)
+	Reset(ctx context.Context, scope string, subject string) error
+	Lockouts(ctx context.Context) ([]LoginLockout, error)
+}
+
+var LoginAttempts LoginThrottle
+
+// InitLoginThrottle uses Redis when a client is available and falls back to
+// an in-process store otherwise, like InitRevocationStore.
+func InitLoginThrottle(client *redis.Client) {
+	if client != nil {
+		LoginAttempts = NewRedisLoginThrottle(client)
+		return
+	}
+
+	LoginAttempts = NewMemoryLoginThrottle()
+}
+
+// loginLockoutDuration applies the progressive backoff: the base lockout
+// when the limit is reached, doubled for every failure after that.
+func loginLockoutDuration(scope string, failures int64) time.Duration {
+	limit := loginMaxAttempts(scope)
+	if failures < limit {
+		return 0
+	}
+
+	base := utils.ParseDurationOrDefault(config.LoginLockoutBase, time.Minute)
+	maximum := utils.ParseDurationOrDefault(config.LoginLockoutMax, time.Hour)
+
+	lockout := base
+	for i := limit; i < failures && lockout < maximum; i++ {
+		lockout *= 2
+	}
+
+	return min(lockout, maximum)
+}
+
+func loginMaxAttempts(scope string) int64 {
+	value := config.LoginMaxAttempts
+	fallback := int64(5)
+	if scope == LoginScopeIP {
+		value = config.LoginIPMaxAttempts
+		fallback = 20
+	}
+
+	limit, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || limit < 1 {
+		return fallback
+	}
+
+	return limit
+}
+
+func loginFailureWindow() time.Duration {
+	return utils.ParseDurationOrDefault(config.LoginFailureWindow, 15*time.Minute)
+}
+
+func IsLoginScope(scope string) bool {
+	return scope == LoginScopeUsername || scope == LoginScopeIP
+}
+
+type RedisLoginThrottle struct {
+	Client *redis.Client
+}
+
+func NewRedisLoginThrottle(client *redis.Client) *RedisLoginThrottle {
+	return &RedisLoginThrottle{Client: client}
+}
+
+func (t *RedisLoginThrottle) LockedFor(ctx context.Context, scope string, subject string) (time.Duration, error) {
+	ttl, err := t.Client.PTTL(ctx, loginLockoutKey(scope, subject)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// PTTL reports negative values for missing keys
+	return max(ttl, 0), nil
+}
+
+func (t *RedisLoginThrottle) RecordFailure(ctx context.Context, scope string, subject string) (time.Duration, error) {
+	failuresKey := loginFailuresKey(scope, subject)
+
+	pipeline := t.Client.TxPipeline()
+	incr := pipeline.Incr(ctx, failuresKey)
+	pipeline.Expire(ctx, failuresKey, loginFailureWindow())
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	lockout := loginLockoutDuration(scope, incr.Val())
+	if lockout == 0 {
+		return 0, nil
+	}
+
+	// Keep counting failures for as long as the lockout lasts so the next
+	// one backs off further
+	pipeline = t.Client.TxPipeline()
+	pipeline.Set(ctx, loginLockoutKey(scope, subject), incr.Val(), lockout)
+	pipeline.Expire(ctx, failuresKey, max(lockout, loginFailureWindow()))
+	if _, err := pipeline.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return lockout, nil
+}
+
+func (t *RedisLoginThrottle) Reset(ctx context.Context, scope string, subject string) error {
+	return t.Client.Del(ctx, loginFailuresKey(scope, subject), loginLockoutKey(scope, subject)).Err()
+}
+
+func (t *RedisLoginThrottle) Lockouts(ctx context.Context) ([]LoginLockout, error) {
+	var lockouts []LoginLockout
+
+	iterator := t.Client.Scan(ctx, 0, "login_lockout:*", 100).Iterator()
+	for iterator.Next(ctx) {
+		key := iterator.Val()
+
+		scope, subject, ok := strings.Cut(strings.TrimPrefix(key, "login_lockout:"), ":")
+		if !ok {
+			continue
+		}
+
+		ttl, err := t.Client.PTTL(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ttl <= 0 {
+			continue
+		}
+
+		failures, err := t.Client.Get(ctx, loginFailuresKey(scope, subject)).Int64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+
+		lockouts = append(lockouts, LoginLockout{
+			Scope:       scope,
+			Subject:     subject,
+			Failures:    failures,
+			LockedUntil: time.Now().Add(ttl),
+		})
+	}
+
+	if err := iterator.Err(); err != nil {
+		return nil, err
+	}
+
+	return lockouts, nil
+}
+
+func loginFailuresKey(scope string, subject string) string {
+	return fmt.Sprintf("login_failures:%s:%s", scope, subject)
+}
+
+func loginLockoutKey(scope string, subject string) string {
+	return fmt.Sprintf("login_lockout:%s:%s", scope, subject)
+}
+
+type memoryLoginEntry struct {
+	failures    int64
+	expiresAt   time.Time
+	lockedUntil time.Time
+}
+
+type MemoryLoginThrottle struct {
+	mu      sync.Mutex
+	entries map[string]*memoryLoginEntry
+}
+
+func NewMemoryLoginThrottle() *MemoryLoginThrottle {
+	return &MemoryLoginThrottle{entries: make(map[string]*memoryLoginEntry)}
+}
+
+func (t *MemoryLoginThrottle) LockedFor(_ context.Context, scope string, subject string) (time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := t.entry(scope, subject)
+	if entry == nil {
+		return 0, nil
+	}
+
+	return max(time.Until(entry.lockedUntil), 0), nil
+}
+
+func (t *MemoryLoginThrottle) RecordFailure(_ context.Context, scope string, subject string) (time.Duration, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := t.entry(scope, subject)
+	if entry == nil {
+		entry = &memoryLoginEntry{}
+		t.entries[loginLockoutKey(scope, subject)] = entry
+	}
+
+	entry.failures++
+	entry.expiresAt = time.Now().Add(loginFailureWindow())
+
+	lockout := loginLockoutDuration(scope, entry.failures)
+	if lockout > 0 {
+		entry.lockedUntil = time.Now().Add(lockout)
+		entry.expiresAt = time.Now().Add(max(lockout, loginFailureWindow()))
+	}
+
+	return lockout, nil
+}
+
+func (t *MemoryLoginThrottle) Reset(_ context.Context, scope string, subject string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries, loginLockoutKey(scope, subject))
+	return nil
+}
+
+func (t *MemoryLoginThrottle) Lockouts(_ context.Context) ([]LoginLockout, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var lockouts []LoginLockout
+	for key, entry := range t.entries {
+		if time.Now().After(entry.lockedUntil) {
+			continue
+		}
+
+		scope, subject, _ := strings.Cut(strings.TrimPrefix(key, "login_lockout:"), ":")
+		lockouts = append(lockouts, LoginLockout{
+			Scope:       scope,
+			Subject:     subject,
+			Failures:    entry.failures,
+			LockedUntil: entry.lockedUntil,
+		})
+	}
+
+	return lockouts, nil
+}
+
+// entry returns the live entry for the subject, dropping it once expired.
+// The caller must hold the lock.
+func (t *MemoryLoginThrottle) entry(scope string, subject string) *memoryLoginEntry {
+	key := loginLockoutKey(scope, subject)
+
+	entry, ok := t.entries[key]
+	if !ok {
+		return nil
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(t.entries, key)
+		return nil
+	}
+
+	return entry
+}
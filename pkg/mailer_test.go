@@ -0,0 +1,210 @@
+package pkg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestInitMailerRequiresDriver(t *testing.T) {
+	defer func(driver string) { config.MailDriver = driver }(config.MailDriver)
+
+	for driver, wantErr := range map[string]bool{"": true, "carrier-pigeon": true, "log": false, "file": false, "smtp": false} {
+		config.MailDriver = driver
+		if err := InitMailer(); (err != nil) != wantErr {
+			t.Errorf("InitMailer() with %q: err = %v, want error %v", driver, err, wantErr)
+		}
+	}
+}
+
+func TestFileMailerWritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	mailer := NewFileMailer(dir, "no-reply@example.com")
+
+	err := mailer.Send(context.Background(), MailMessage{To: "jane@example.com", Subject: "Hello", Body: "line one\nline two"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one .eml file, got %v (%v)", files, err)
+	}
+
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"To: jane@example.com\r\n", "Subject: Hello\r\n", "line one\r\nline two"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("message does not contain %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestFileMailerRejectsHeaderInjection(t *testing.T) {
+	mailer := NewFileMailer(t.TempDir(), "no-reply@example.com")
+
+	err := mailer.Send(context.Background(), MailMessage{To: "jane@example.com\r\nBcc: eve@example.com", Subject: "Hello"})
+	if err == nil {
+		t.Error("Send() accepted a recipient with a line break")
+	}
+}
+
+func TestLogMailerOmitsBody(t *testing.T) {
+	defer func(logger *zap.Logger) { config.Logger = logger }(config.Logger)
+	core, logs := observer.New(zap.InfoLevel)
+	config.Logger = zap.New(core)
+
+	secret := "https://example.com/reset?token=live-token"
+	if err := NewLogMailer("no-reply@example.com").Send(context.Background(), MailMessage{To: "jane@example.com", Subject: "Reset", Body: secret}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected one log entry, got %d", len(entries))
+	}
+	for key, value := range entries[0].ContextMap() {
+		if text, ok := value.(string); ok && strings.Contains(text, "live-token") {
+			t.Errorf("log field %q leaks the message body", key)
+		}
+	}
+}
+
+// startSMTPServer accepts one connection and hands it to serve. It returns
+// the host and port to dial.
+func startSMTPServer(t *testing.T, serve func(conn net.Conn)) (string, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		serve(conn)
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return host, port
+}
+
+func TestSMTPMailerDelivers(t *testing.T) {
+	received := make(chan string, 1)
+	host, port := startSMTPServer(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+		reply("220 test ESMTP")
+		var data strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch command := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(command, "EHLO"):
+				reply("250 test")
+			case strings.HasPrefix(command, "MAIL"), strings.HasPrefix(command, "RCPT"):
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 Go ahead")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 Queued")
+			case command == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Unsupported")
+			}
+		}
+	})
+
+	mailer := NewSMTPMailer(host, port, "", "", "no-reply@example.com", 5*time.Second)
+	if err := mailer.Send(context.Background(), MailMessage{To: "jane@example.com", Subject: "Hello", Body: "line one\nline two"}); err != nil {
+		t.Fatal(err)
+	}
+
+	message := <-received
+	for _, want := range []string{"To: jane@example.com\r\n", "Subject: Hello\r\n", "line one\r\nline two"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("message does not contain %q:\n%s", want, message)
+		}
+	}
+}
+
+func TestSMTPMailerGivesUpOnStalledServer(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := map[string]struct {
+		timeout time.Duration
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		"timeout": {
+			timeout: 100 * time.Millisecond,
+			ctx:     func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			wantErr: context.DeadlineExceeded,
+		},
+		"cancelled context": {
+			timeout: time.Minute,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(100*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// The server accepts the connection but never greets
+			host, port := startSMTPServer(t, func(conn net.Conn) { <-release })
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			mailer := NewSMTPMailer(host, port, "", "", "no-reply@example.com", tt.timeout)
+			started := time.Now()
+			err := mailer.Send(ctx, MailMessage{To: "jane@example.com", Subject: "Hello", Body: "Hi"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(started); elapsed > 5*time.Second {
+				t.Errorf("Send() took %s to give up", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package pkg
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+	"github.com/radenadri/go-boilerplate/utils"
+)
+
+var ErrInvalidMFAToken = errors.New("invalid mfa token")
+
+// MFAPendingClaims are carried by the short-lived token returned by a
+// password login on accounts with two-factor authentication. It proves the
+// password step only and is refused everywhere except the second login step.
+type MFAPendingClaims struct {
+	Type string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func GenerateMFAPendingToken(user models.User) (string, error) {
+	tokenID, err := GenerateTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := &MFAPendingClaims{
+		Type:             TokenTypeMFAPending,
+		RegisteredClaims: registeredClaims(tokenID, user.ID, now, now.Add(MFAPendingTokenTTL())),
+	}
+	return signToken(claims)
+}
+
+func ParseMFAPendingToken(tokenString string) (*MFAPendingClaims, error) {
+	claims := &MFAPendingClaims{}
+
+	if err := parseToken(tokenString, claims); err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	if claims.Type != TokenTypeMFAPending || claims.ID == "" {
+		return nil, ErrInvalidMFAToken
+	}
+
+	return claims, nil
+}
+
+func MFAPendingTokenTTL() time.Duration {
+	return utils.ParseDurationOrDefault(config.MFAPendingTokenTTL, 5*time.Minute)
+}
@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/utils"
+)
+
+// OAuthProvider signs users in through an external identity provider with
+// the authorization code flow. OIDCProvider covers any OpenID Connect
+// provider; others can be added by implementing this interface and
+// registering them with RegisterOAuthProvider. Errors caused by the provider
+// being unreachable or failing must match ErrOAuthProviderUnavailable, so they
+// are told apart from rejected logins.
+type OAuthProvider interface {
+	Name() string
+	AuthorizationURL(ctx context.Context, state string, authorization OAuthAuthorization) (string, error)
+	Exchange(ctx context.Context, code string, authorization OAuthAuthorization) (*ExternalIdentity, error)
+}
+
+// OAuthAuthorization is the server-side half of a login in progress. It is
+// stored under the state parameter until the provider redirects back.
+type OAuthAuthorization struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// ExternalIdentity is the user as described by an identity provider.
+type ExternalIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+var OAuthProviders = map[string]OAuthProvider{}
+
+var ErrOAuthProviderUnavailable = errors.New("oauth provider unavailable")
+
+// InitOAuthProviders registers an OIDC provider for every name listed in
+// OAUTH_PROVIDERS, configured from OIDC_<NAME>_ISSUER, _CLIENT_ID,
+// _CLIENT_SECRET, _REDIRECT_URL and _SCOPES.
+func InitOAuthProviders() error {
+	for _, name := range strings.Split(config.OAuthProviders, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		oidcConfig := OIDCConfig{
+			Name:         name,
+			IssuerURL:    config.GetEnvOrDefault(prefix+"ISSUER", ""),
+			ClientID:     config.GetEnvOrDefault(prefix+"CLIENT_ID", ""),
+			ClientSecret: config.GetEnvOrDefault(prefix+"CLIENT_SECRET", ""),
+			RedirectURL:  config.GetEnvOrDefault(prefix+"REDIRECT_URL", ""),
+			Scopes:       strings.Fields(config.GetEnvOrDefault(prefix+"SCOPES", "openid email profile")),
+		}
+
+		if oidcConfig.IssuerURL == "" || oidcConfig.ClientID == "" || oidcConfig.RedirectURL == "" {
+			return fmt.Errorf("OAuth provider %q needs %sISSUER, %sCLIENT_ID and %sREDIRECT_URL", name, prefix, prefix, prefix)
+		}
+
+		RegisterOAuthProvider(NewOIDCProvider(oidcConfig))
+	}
+
+	return nil
+}
+
+func RegisterOAuthProvider(provider OAuthProvider) {
+	OAuthProviders[provider.Name()] = provider
+}
+
+// NewOAuthAuthorization creates the nonce and PKCE code verifier for a new
+// login with the given provider.
+func NewOAuthAuthorization(provider string) (OAuthAuthorization, error) {
+	nonce, _, err := GenerateSecureToken()
+	if err != nil {
+		return OAuthAuthorization{}, err
+	}
+
+	codeVerifier, _, err := GenerateSecureToken()
+	if err != nil {
+		return OAuthAuthorization{}, err
+	}
+
+	return OAuthAuthorization{
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	}, nil
+}
+
+// CodeChallenge derives the S256 PKCE challenge (RFC 7636) sent with the
+// authorization request.
+func (a OAuthAuthorization) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(a.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func OAuthStateTTL() time.Duration {
+	return utils.ParseDurationOrDefault(config.OAuthStateTTL, 10*time.Minute)
+}
@@ -0,0 +1,118 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// OAuthStateStore keeps OAuth logins in progress, keyed by their state
+// parameter. Consume returns nil once a state has been used or has expired,
+// so every state is accepted at most once.
+type OAuthStateStore interface {
+	Save(ctx context.Context, state string, authorization OAuthAuthorization, ttl time.Duration) error
+	Consume(ctx context.Context, state string) (*OAuthAuthorization, error)
+}
+
+var OAuthStates OAuthStateStore
+
+// InitOAuthStateStore uses Redis when a client is available and falls back to
+// an in-process store otherwise.
+func InitOAuthStateStore(client *redis.Client) {
+	if client != nil {
+		OAuthStates = NewRedisOAuthStateStore(client)
+		return
+	}
+
+	OAuthStates = NewMemoryOAuthStateStore()
+}
+
+type RedisOAuthStateStore struct {
+	Client *redis.Client
+}
+
+func NewRedisOAuthStateStore(client *redis.Client) *RedisOAuthStateStore {
+	return &RedisOAuthStateStore{Client: client}
+}
+
+func (s *RedisOAuthStateStore) Save(ctx context.Context, state string, authorization OAuthAuthorization, ttl time.Duration) error {
+	encoded, err := json.Marshal(authorization)
+	if err != nil {
+		return err
+	}
+
+	return s.Client.Set(ctx, oauthStateKey(state), encoded, ttl).Err()
+}
+
+func (s *RedisOAuthStateStore) Consume(ctx context.Context, state string) (*OAuthAuthorization, error) {
+	encoded, err := s.Client.GetDel(ctx, oauthStateKey(state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var authorization OAuthAuthorization
+	if err := json.Unmarshal(encoded, &authorization); err != nil {
+		return nil, err
+	}
+
+	return &authorization, nil
+}
+
+// oauthStateKey stores states by hash so the raw values never sit in Redis.
+func oauthStateKey(state string) string {
+	return "oauth_state:" + HashToken(state)
+}
+
+type oauthStateEntry struct {
+	authorization OAuthAuthorization
+	expiresAt     time.Time
+}
+
+type MemoryOAuthStateStore struct {
+	mu      sync.Mutex
+	entries map[string]oauthStateEntry
+}
+
+func NewMemoryOAuthStateStore() *MemoryOAuthStateStore {
+	return &MemoryOAuthStateStore{entries: make(map[string]oauthStateEntry)}
+}
+
+func (s *MemoryOAuthStateStore) Save(_ context.Context, state string, authorization OAuthAuthorization, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.entries[oauthStateKey(state)] = oauthStateEntry{authorization: authorization, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryOAuthStateStore) Consume(_ context.Context, state string) (*OAuthAuthorization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := oauthStateKey(state)
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(s.entries, key)
+
+	if time.Now().After(entry.expiresAt) {
+		return nil, nil
+	}
+
+	return &entry.authorization, nil
+}
@@ -0,0 +1,459 @@
+package pkg
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/radenadri/go-boilerplate/config"
+	"github.com/radenadri/go-boilerplate/utils"
+)
+
+var (
+	ErrOIDCDiscovery      = errors.New("oidc discovery failed")
+	ErrOIDCTokenExchange  = errors.New("oidc token exchange failed")
+	ErrOIDCIDTokenInvalid = errors.New("invalid oidc id token")
+)
+
+// oidcSigningAlgorithms are the ID token algorithms accepted from providers.
+// Symmetric algorithms and "none" are never accepted.
+var oidcSigningAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+const (
+	// oidcResponseLimit caps how much of a provider response is read.
+	oidcResponseLimit = 1 << 20
+
+	// oidcKeyRefreshInterval limits how often an unknown "kid" triggers a
+	// fresh download of the provider's keys.
+	oidcKeyRefreshInterval = time.Minute
+)
+
+type OIDCConfig struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	HTTPClient   *http.Client
+}
+
+// OIDCProvider implements OAuthProvider for any OpenID Connect provider. The
+// endpoints and signing keys are read from the issuer's discovery document
+// on first use. Logins use the authorization code flow with PKCE (S256), and
+// the ID token's signature, issuer, audience, expiry and nonce are checked.
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *oidcMetadata
+	keys          map[string]oidcKey
+	keysFetchedAt time.Time
+}
+
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcKey struct {
+	key       crypto.PublicKey
+	algorithm string
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type oidcIDTokenClaims struct {
+	Nonce             string   `json:"nonce"`
+	AuthorizedParty   string   `json:"azp"`
+	Email             string   `json:"email"`
+	EmailVerified     oidcBool `json:"email_verified"`
+	Name              string   `json:"name"`
+	PreferredUsername string   `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+// oidcBool accepts email_verified as a JSON boolean or as the string "true",
+// which some providers send instead.
+type oidcBool bool
+
+func (b *oidcBool) UnmarshalJSON(data []byte) error {
+	*b = oidcBool(strings.Trim(string(data), `"`) == "true")
+	return nil
+}
+
+func NewOIDCProvider(oidcConfig OIDCConfig) *OIDCProvider {
+	client := oidcConfig.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &OIDCProvider{
+		config: oidcConfig,
+		client: client,
+		keys:   make(map[string]oidcKey),
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *OIDCProvider) AuthorizationURL(ctx context.Context, state string, authorization OAuthAuthorization) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authorizationURL, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+
+	query := authorizationURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", authorization.Nonce)
+	query.Set("code_challenge", authorization.CodeChallenge())
+	query.Set("code_challenge_method", "S256")
+	authorizationURL.RawQuery = query.Encode()
+
+	return authorizationURL.String(), nil
+}
+
+// Exchange redeems the authorization code at the token endpoint and returns
+// the identity described by the verified ID token.
+func (p *OIDCProvider) Exchange(ctx context.Context, code string, authorization OAuthAuthorization) (*ExternalIdentity, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", authorization.CodeVerifier)
+	if p.config.ClientSecret == "" {
+		form.Set("client_id", p.config.ClientID)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		// RFC 6749 section 2.3.1 form-encodes the credentials before Basic auth
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w: %v", ErrOIDCTokenExchange, ErrOAuthProviderUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: %w: %s", ErrOIDCTokenExchange, ErrOAuthProviderUnavailable, response.Status)
+	}
+
+	var tokenResponse oidcTokenResponse
+	if err := json.NewDecoder(io.LimitReader(response.Body, oidcResponseLimit)).Decode(&tokenResponse); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrOIDCTokenExchange, response.Status, err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s: %s %s", ErrOIDCTokenExchange, response.Status, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+
+	if tokenResponse.IDToken == "" {
+		return nil, fmt.Errorf("%w: response has no id_token", ErrOIDCTokenExchange)
+	}
+
+	claims, err := p.verifyIDToken(ctx, metadata, tokenResponse.IDToken, authorization.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExternalIdentity{
+		Provider:          p.config.Name,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     bool(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, metadata *oidcMetadata, idToken string, nonce string) (*oidcIDTokenClaims, error) {
+	claims := &oidcIDTokenClaims{}
+
+	token, err := jwt.ParseWithClaims(idToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return p.verificationKey(ctx, token)
+	},
+		jwt.WithValidMethods(oidcSigningAlgorithms),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithLeeway(utils.ParseDurationOrDefault(config.JWTLeeway, 0)),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if errors.Is(err, ErrOAuthProviderUnavailable) {
+		// The key set could not be fetched, which says nothing about the token
+		return nil, err
+	}
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCIDTokenInvalid, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrOIDCIDTokenInvalid)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCIDTokenInvalid)
+	}
+
+	// A token issued to several audiences must name us as the party it was
+	// issued for
+	if (len(claims.Audience) > 1 || claims.AuthorizedParty != "") && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: azp mismatch", ErrOIDCIDTokenInvalid)
+	}
+
+	return claims, nil
+}
+
+// verificationKey finds the provider key named by the token "kid", fetching
+// the key set again when the kid is unknown, which is how providers roll
+// their keys. The key type must match the token algorithm.
+func (p *OIDCProvider) verificationKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key, ok := p.findKey(keyID)
+	if !ok && time.Since(p.keysFetchedAt) >= oidcKeyRefreshInterval {
+		if err := p.fetchKeys(ctx); err != nil {
+			return nil, err
+		}
+		key, ok = p.findKey(keyID)
+	}
+
+	if !ok {
+		return nil, ErrTokenSignatureInvalid
+	}
+
+	if key.algorithm != "" && key.algorithm != token.Method.Alg() {
+		return nil, ErrTokenSignatureInvalid
+	}
+
+	compatible := false
+	switch key.key.(type) {
+	case *rsa.PublicKey:
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			compatible = true
+		}
+	case *ecdsa.PublicKey:
+		_, compatible = token.Method.(*jwt.SigningMethodECDSA)
+	case ed25519.PublicKey:
+		_, compatible = token.Method.(*jwt.SigningMethodEd25519)
+	}
+
+	if !compatible {
+		return nil, ErrTokenSignatureInvalid
+	}
+
+	return key.key, nil
+}
+
+// findKey must be called with p.mu held. Tokens without a kid are accepted
+// only when the provider publishes a single key.
+func (p *OIDCProvider) findKey(keyID string) (oidcKey, bool) {
+	if keyID == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[keyID]
+	return key, ok
+}
+
+// fetchKeys must be called with p.mu held and after discovery.
+func (p *OIDCProvider) fetchKeys(ctx context.Context) error {
+	var jwks JWKSet
+	if err := p.getJSON(ctx, p.metadata.JWKSURI, &jwks); err != nil {
+		return err
+	}
+
+	keys := make(map[string]oidcKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := parseJWK(jwk)
+		if err != nil {
+			// Skip key types we cannot use rather than failing every login
+			continue
+		}
+
+		keys[jwk.KeyID] = oidcKey{key: publicKey, algorithm: jwk.Algorithm}
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	return nil
+}
+
+// discover loads and caches the issuer's discovery document. The issuer it
+// names must be exactly the configured one.
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata oidcMetadata
+	discoveryURL := strings.TrimSuffix(p.config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, discoveryURL, &metadata); err != nil {
+		return nil, err
+	}
+
+	if metadata.Issuer != p.config.IssuerURL {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrOIDCDiscovery, metadata.Issuer, p.config.IssuerURL)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: discovery document is missing endpoints", ErrOIDCDiscovery)
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, target string, destination interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("%w: %w: %v", ErrOIDCDiscovery, ErrOAuthProviderUnavailable, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %w: GET %s: %s", ErrOIDCDiscovery, ErrOAuthProviderUnavailable, target, response.Status)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: GET %s: %s", ErrOIDCDiscovery, target, response.Status)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(response.Body, oidcResponseLimit)).Decode(destination); err != nil {
+		return fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+
+	return nil
+}
+
+// parseJWK converts a published RSA, EC or Ed25519 key (RFC 7517, RFC 8037).
+func parseJWK(jwk JWK) (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA key")
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, errors.New("invalid EC key")
+		}
+
+		return publicKey, nil
+	case "OKP":
+		if jwk.Curve != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Curve)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.KeyType)
+	}
+}
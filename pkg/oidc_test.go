@@ -0,0 +1,424 @@
+package pkg
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testOIDCClientID     = "client"
+	testOIDCClientSecret = "s3cr%t"
+	testOIDCRedirectURL  = "http://app.test/callback"
+)
+
+// fakeOIDCServer serves a discovery document, a key set and a token endpoint
+// that answers every code with the configured ID token. A non-zero
+// jwksStatus or tokenStatus makes that endpoint fail with the status instead.
+type fakeOIDCServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	issuer      string
+	keys        []JWK
+	idToken     string
+	form        url.Values
+	basicUser   string
+	basicPass   string
+	jwksHits    int
+	jwksStatus  int
+	tokenStatus int
+}
+
+func newFakeOIDCServer(t *testing.T) *fakeOIDCServer {
+	t.Helper()
+
+	server := &fakeOIDCServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		issuer := server.issuer
+		server.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"jwks_uri":               server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+
+		server.jwksHits++
+		if server.jwksStatus != 0 {
+			w.WriteHeader(server.jwksStatus)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(JWKSet{Keys: server.keys})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		server.mu.Lock()
+		defer server.mu.Unlock()
+
+		server.form = r.PostForm
+		server.basicUser, server.basicPass, _ = r.BasicAuth()
+		if server.tokenStatus != 0 {
+			w.WriteHeader(server.tokenStatus)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": server.idToken})
+	})
+
+	server.Server = httptest.NewServer(mux)
+	server.issuer = server.URL
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *fakeOIDCServer) setKeys(keys ...JWK) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+}
+
+func (s *fakeOIDCServer) setIDToken(idToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.idToken = idToken
+}
+
+func newTestOIDCKey(t *testing.T, keyID string) (*rsa.PrivateKey, JWK) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return privateKey, JWK{
+		KeyType:   "RSA",
+		Use:       "sig",
+		KeyID:     keyID,
+		Algorithm: "RS256",
+		N:         base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+	}
+}
+
+func signTestIDToken(t *testing.T, method jwt.SigningMethod, key interface{}, keyID string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if keyID != "" {
+		token.Header["kid"] = keyID
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func validTestIDTokenClaims(issuer string, nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                issuer,
+		"aud":                testOIDCClientID,
+		"sub":                "subject-1",
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(time.Minute).Unix(),
+		"nonce":              nonce,
+		"email":              "jane@example.com",
+		"email_verified":     "true",
+		"name":               "Jane Doe",
+		"preferred_username": "jane",
+	}
+}
+
+func newTestOIDCProvider(server *fakeOIDCServer) *OIDCProvider {
+	return NewOIDCProvider(OIDCConfig{
+		Name:         "fake",
+		IssuerURL:    server.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: testOIDCClientSecret,
+		RedirectURL:  testOIDCRedirectURL,
+		Scopes:       []string{"openid", "email"},
+		HTTPClient:   server.Client(),
+	})
+}
+
+func newTestOAuthAuthorization(t *testing.T) OAuthAuthorization {
+	t.Helper()
+
+	authorization, err := NewOAuthAuthorization("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return authorization
+}
+
+func TestOIDCProviderAuthorizationURL(t *testing.T) {
+	server := newFakeOIDCServer(t)
+	provider := newTestOIDCProvider(server)
+	authorization := newTestOAuthAuthorization(t)
+
+	authorizationURL, err := provider.AuthorizationURL(context.Background(), "state-1", authorization)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+
+	expected := map[string]string{
+		"response_type":         "code",
+		"client_id":             testOIDCClientID,
+		"redirect_uri":          testOIDCRedirectURL,
+		"scope":                 "openid email",
+		"state":                 "state-1",
+		"nonce":                 authorization.Nonce,
+		"code_challenge":        authorization.CodeChallenge(),
+		"code_challenge_method": "S256",
+	}
+	for name, value := range expected {
+		if got := query.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	server := newFakeOIDCServer(t)
+	signingKey, jwk := newTestOIDCKey(t, "key-1")
+	server.setKeys(jwk)
+	provider := newTestOIDCProvider(server)
+	authorization := newTestOAuthAuthorization(t)
+
+	server.setIDToken(signTestIDToken(t, jwt.SigningMethodRS256, signingKey, "key-1", validTestIDTokenClaims(server.URL, authorization.Nonce)))
+
+	identity, err := provider.Exchange(context.Background(), "code-1", authorization)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ExternalIdentity{
+		Provider:          "fake",
+		Subject:           "subject-1",
+		Email:             "jane@example.com",
+		EmailVerified:     true,
+		Name:              "Jane Doe",
+		PreferredUsername: "jane",
+	}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if got := server.form.Get("code_verifier"); got != authorization.CodeVerifier {
+		t.Errorf("code_verifier = %q, want %q", got, authorization.CodeVerifier)
+	}
+	if got := server.form.Get("code"); got != "code-1" {
+		t.Errorf("code = %q, want %q", got, "code-1")
+	}
+	if got := server.form.Get("redirect_uri"); got != testOIDCRedirectURL {
+		t.Errorf("redirect_uri = %q, want %q", got, testOIDCRedirectURL)
+	}
+
+	// The credentials are form-encoded before Basic auth
+	clientID, _ := url.QueryUnescape(server.basicUser)
+	clientSecret, _ := url.QueryUnescape(server.basicPass)
+	if clientID != testOIDCClientID || clientSecret != testOIDCClientSecret {
+		t.Errorf("client credentials = %q:%q, want %q:%q", clientID, clientSecret, testOIDCClientID, testOIDCClientSecret)
+	}
+}
+
+func TestOIDCProviderRejectsInvalidIDTokens(t *testing.T) {
+	server := newFakeOIDCServer(t)
+	signingKey, jwk := newTestOIDCKey(t, "key-1")
+	server.setKeys(jwk)
+	provider := newTestOIDCProvider(server)
+	authorization := newTestOAuthAuthorization(t)
+
+	rs256 := func(modify func(jwt.MapClaims)) string {
+		claims := validTestIDTokenClaims(server.URL, authorization.Nonce)
+		modify(claims)
+		return signTestIDToken(t, jwt.SigningMethodRS256, signingKey, "key-1", claims)
+	}
+
+	tests := map[string]string{
+		"nonce mismatch": rs256(func(claims jwt.MapClaims) { claims["nonce"] = "other-nonce" }),
+		"missing nonce":  rs256(func(claims jwt.MapClaims) { delete(claims, "nonce") }),
+		"wrong audience": rs256(func(claims jwt.MapClaims) { claims["aud"] = "other-client" }),
+		"several audiences without azp": rs256(func(claims jwt.MapClaims) {
+			claims["aud"] = []string{testOIDCClientID, "other-client"}
+		}),
+		"wrong azp":      rs256(func(claims jwt.MapClaims) { claims["azp"] = "other-client" }),
+		"wrong issuer":   rs256(func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example" }),
+		"expired":        rs256(func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }),
+		"missing expiry": rs256(func(claims jwt.MapClaims) { delete(claims, "exp") }),
+		"missing sub":    rs256(func(claims jwt.MapClaims) { delete(claims, "sub") }),
+		"alg none": signTestIDToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "key-1",
+			validTestIDTokenClaims(server.URL, authorization.Nonce)),
+		"alg HS256": signTestIDToken(t, jwt.SigningMethodHS256, []byte(jwk.N), "key-1",
+			validTestIDTokenClaims(server.URL, authorization.Nonce)),
+	}
+
+	for name, idToken := range tests {
+		t.Run(name, func(t *testing.T) {
+			server.setIDToken(idToken)
+
+			_, err := provider.Exchange(context.Background(), "code", authorization)
+			if !errors.Is(err, ErrOIDCIDTokenInvalid) {
+				t.Fatalf("Exchange() error = %v, want %v", err, ErrOIDCIDTokenInvalid)
+			}
+		})
+	}
+}
+
+func TestOIDCProviderAcceptsAuthorizedPartyForSeveralAudiences(t *testing.T) {
+	server := newFakeOIDCServer(t)
+	signingKey, jwk := newTestOIDCKey(t, "key-1")
+	server.setKeys(jwk)
+	provider := newTestOIDCProvider(server)
+	authorization := newTestOAuthAuthorization(t)
+
+	claims := validTestIDTokenClaims(server.URL, authorization.Nonce)
+	claims["aud"] = []string{testOIDCClientID, "other-client"}
+	claims["azp"] = testOIDCClientID
+	server.setIDToken(signTestIDToken(t, jwt.SigningMethodRS256, signingKey, "key-1", claims))
+
+	if _, err := provider.Exchange(context.Background(), "code", authorization); err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+}
+
+func TestOIDCProviderRefetchesKeysForUnknownKeyID(t *testing.T) {
+	server := newFakeOIDCServer(t)
+	oldKey, oldJWK := newTestOIDCKey(t, "key-1")
+	server.setKeys(oldJWK)
+	provider := newTestOIDCProvider(server)
+	authorization := newTestOAuthAuthorization(t)
+
+	server.setIDToken(signTestIDToken(t, jwt.SigningMethodRS256, oldKey, "key-1", validTestIDTokenClaims(server.URL, authorization.Nonce)))
+	if _, err := provider.Exchange(context.Background(), "code", authorization); err != nil {
+		t.Fatal(err)
+	}
+
+	// The provider rolls its keys
+	newKey, newJWK := newTestOIDCKey(t, "key-2")
+	server.setKeys(oldJWK, newJWK)
+	server.setIDToken(signTestIDToken(t, jwt.SigningMethodRS256, newKey, "key-2", validTestIDTokenClaims(server.URL, authorization.Nonce)))
+
+	// Right after a fetch an unknown kid does not trigger another download
+	if _, err := provider.Exchange(context.Background(), "code", authorization); !errors.Is(err, ErrOIDCIDTokenInvalid) {
+		t.Fatalf("Exchange() error = %v, want %v", err, ErrOIDCIDTokenInvalid)
+	}
+
+	provider.mu.Lock()
+	provider.keysFetchedAt = time.Now().Add(-oidcKeyRefreshInterval)
+	provider.mu.Unlock()
+
+	if _, err := provider.Exchange(context.Background(), "code", authorization); err != nil {
+		t.Fatalf("Exchange() after key rotation error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if server.jwksHits != 2 {
+		t.Errorf("key set fetched %d times, want 2", server.jwksHits)
+	}
+}
+
+func TestOIDCProviderRejectsIssuerMismatchInDiscovery(t *testing.T) {
+	server := newFakeOIDCServer(t)
+	server.mu.Lock()
+	server.issuer = "https://evil.example"
+	server.mu.Unlock()
+	provider := newTestOIDCProvider(server)
+
+	_, err := provider.AuthorizationURL(context.Background(), "state", newTestOAuthAuthorization(t))
+	if !errors.Is(err, ErrOIDCDiscovery) {
+		t.Fatalf("AuthorizationURL() error = %v, want %v", err, ErrOIDCDiscovery)
+	}
+
+	_, err = provider.Exchange(context.Background(), "code", newTestOAuthAuthorization(t))
+	if !errors.Is(err, ErrOIDCDiscovery) {
+		t.Fatalf("Exchange() error = %v, want %v", err, ErrOIDCDiscovery)
+	}
+}
+
+func TestOIDCProviderReportsProviderOutages(t *testing.T) {
+	tests := []struct {
+		name            string
+		jwksStatus      int
+		tokenStatus     int
+		closed          bool
+		wantErr         error
+		wantUnavailable bool
+	}{
+		{name: "rejected code", tokenStatus: http.StatusBadRequest, wantErr: ErrOIDCTokenExchange},
+		{name: "token endpoint failing", tokenStatus: http.StatusBadGateway, wantErr: ErrOIDCTokenExchange, wantUnavailable: true},
+		{name: "key set failing", jwksStatus: http.StatusServiceUnavailable, wantErr: ErrOIDCDiscovery, wantUnavailable: true},
+		{name: "unreachable", closed: true, wantErr: ErrOIDCDiscovery, wantUnavailable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeOIDCServer(t)
+			signingKey, jwk := newTestOIDCKey(t, "key-1")
+			server.setKeys(jwk)
+			provider := newTestOIDCProvider(server)
+			authorization := newTestOAuthAuthorization(t)
+
+			server.setIDToken(signTestIDToken(t, jwt.SigningMethodRS256, signingKey, "key-1", validTestIDTokenClaims(server.URL, authorization.Nonce)))
+			server.mu.Lock()
+			server.jwksStatus, server.tokenStatus = tt.jwksStatus, tt.tokenStatus
+			server.mu.Unlock()
+			if tt.closed {
+				server.Close()
+			}
+
+			_, err := provider.Exchange(context.Background(), "code", authorization)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Exchange() error = %v, want %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrOAuthProviderUnavailable) != tt.wantUnavailable {
+				t.Errorf("Exchange() error = %v, matches ErrOAuthProviderUnavailable = %v, want %v", err, !tt.wantUnavailable, tt.wantUnavailable)
+			}
+			if errors.Is(err, ErrOIDCIDTokenInvalid) {
+				t.Errorf("Exchange() error = %v, want it not to blame the ID token", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package pkg
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPerPage = 10
+	MaxPerPage     = 100
+)
+
+// PageParams describes the requested page and where it was requested from, so
+// pagination links point back at the real route with the caller's filters.
+type PageParams struct {
+	Page    int
+	PerPage int
+	Path    string
+	Query   url.Values
+}
+
+// NewPageParams reads page and per_page from the request, clamping them to
+// sane bounds instead of failing on bad input.
+func NewPageParams(request *http.Request) PageParams {
+	query := request.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+
+	return PageParams{
+		Page:    page,
+		PerPage: perPage,
+		Path:    request.URL.Path,
+		Query:   query,
+	}
+}
+
+func (p PageParams) Offset() int {
+	return (p.Page - 1) * p.PerPage
+}
+
+// Link returns the request URL with the given parameters replaced and every
+// other parameter preserved.
+func (p PageParams) Link(overrides url.Values) string {
+	values := url.Values{}
+	for key, value := range p.Query {
+		values[key] = value
+	}
+	for key, value := range overrides {
+		values[key] = value
+	}
+
+	return fmt.Sprintf("%s?%s", p.Path, values.Encode())
+}
+
+func (p PageParams) pageLink(page int) string {
+	return p.Link(url.Values{
+		"page":     {strconv.Itoa(page)},
+		"per_page": {strconv.Itoa(p.PerPage)},
+	})
+}
+
+// Paginate counts the rows matched by query and loads the requested page.
+// Ordering must already be applied to query for pages to be stable.
+func Paginate[T any](query *gorm.DB, params PageParams) ([]T, int64, error) {
+	var totalItems int64
+	var items []T
+
+	if err := query.Session(&gorm.Session{}).Model(new(T)).Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Session(&gorm.Session{}).Limit(params.PerPage).Offset(params.Offset()).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return items, totalItems, nil
+}
+
+// NewPaginatedResponse wraps a page of items with pagination details and
+// links built from params.
+func NewPaginatedResponse[T any](items []T, totalItems int64, params PageParams) *response.PaginatedResponse[T] {
+	totalPages := int(math.Ceil(float64(totalItems) / float64(params.PerPage)))
+	hasNextPage := params.Page < totalPages
+	hasPreviousPage := params.Page > 1
+
+	paginatedResponse := &response.PaginatedResponse[T]{
+		Success: true,
+		Data:    items,
+		Pagination: response.Pagination{
+			TotalItems:      int(totalItems),
+			TotalPages:      totalPages,
+			CurrentPage:     params.Page,
+			ItemsPerPage:    params.PerPage,
+			HasNextPage:     hasNextPage,
+			HasPreviousPage: hasPreviousPage,
+		},
+		Links: response.Links{
+			Self:  params.pageLink(params.Page),
+			First: params.pageLink(1),
+			Last:  params.pageLink(max(totalPages, 1)),
+		},
+	}
+
+	if hasNextPage {
+		paginatedResponse.Pagination.NextPage = params.Page + 1
+		paginatedResponse.Links.Next = params.pageLink(params.Page + 1)
+	}
+
+	if hasPreviousPage {
+		previousPage := params.Page - 1
+		previous := params.pageLink(previousPage)
+		paginatedResponse.Pagination.PreviousPage = &previousPage
+		paginatedResponse.Links.Previous = &previous
+	}
+
+	return paginatedResponse
+}
+
+// LinkHeader formats links as an RFC 8288 Link header value.
+func LinkHeader(links response.Links) string {
+	var parts []string
+
+	add := func(link string, rel string) {
+		if link != "" {
+			parts = append(parts, fmt.Sprintf(`<%s>; rel="%s"`, link, rel))
+		}
+	}
+
+	add(links.Self, "self")
+	add(links.First, "first")
+	if links.Previous != nil {
+		add(*links.Previous, "prev")
+	}
+	add(links.Next, "next")
+	add(links.Last, "last")
+
+	return strings.Join(parts, ", ")
+}
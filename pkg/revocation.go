@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenRevocationStore keeps the IDs (jti) of tokens that were revoked before
+// their natural expiry. Entries only need to live until the token expires.
+//
+// RevokeUserTokens revokes every token of a user issued before a point in
+// time, which is how a password reset signs the user out everywhere. The
+// cutoff only needs to outlive the longest-lived access token.
+type TokenRevocationStore interface {
+	Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	RevokeUserTokens(ctx context.Context, userID uint, issuedBefore time.Time) error
+	UserTokensRevokedBefore(ctx context.Context, userID uint) (time.Time, error)
+}
+
+var RevokedTokens TokenRevocationStore
+
+// InitRevocationStore uses Redis when a client is available and falls back to
+// an in-process store otherwise (tests, single-instance local runs).
+func InitRevocationStore(client *redis.Client) {
+	if client != nil {
+		RevokedTokens = NewRedisRevocationStore(client)
+		return
+	}
+
+	RevokedTokens = NewMemoryRevocationStore()
+}
+
+type RedisRevocationStore struct {
+	Client *redis.Client
+}
+
+func NewRedisRevocationStore(client *redis.Client) *RedisRevocationStore {
+	return &RedisRevocationStore{Client: client}
+}
+
+func (s *RedisRevocationStore) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	return s.Client.Set(ctx, revocationKey(tokenID), 1, ttl).Err()
+}
+
+func (s *RedisRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	count, err := s.Client.Exists(ctx, revocationKey(tokenID)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *RedisRevocationStore) RevokeUserTokens(ctx context.Context, userID uint, issuedBefore time.Time) error {
+	return s.Client.Set(ctx, userRevocationKey(userID), issuedBefore.Unix(), AccessTokenTTL()).Err()
+}
+
+// UserTokensRevokedBefore returns the zero time when no cutoff is set.
+func (s *RedisRevocationStore) UserTokensRevokedBefore(ctx context.Context, userID uint) (time.Time, error) {
+	seconds, err := s.Client.Get(ctx, userRevocationKey(userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(seconds, 0), nil
+}
+
+func revocationKey(tokenID string) string {
+	return fmt.Sprintf("revoked_token:%s", tokenID)
+}
+
+func userRevocationKey(userID uint) string {
+	return fmt.Sprintf("revoked_user_tokens:%d", userID)
+}
+
+type userCutoff struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+type MemoryRevocationStore struct {
+	mu          sync.Mutex
+	entries     map[string]time.Time
+	userCutoffs map[uint]userCutoff
+}
+
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		entries:     make(map[string]time.Time),
+		userCutoffs: make(map[uint]userCutoff),
+	}
+}
+
+func (s *MemoryRevocationStore) Revoke(_ context.Context, tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Now().After(expiresAt) {
+		return nil
+	}
+
+	s.entries[tokenID] = expiresAt
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsRevoked(_ context.Context, tokenID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, ok := s.entries[tokenID]
+	if !ok {
+		return false, nil
+	}
+
+	if time.Now().After(expiresAt) {
+		delete(s.entries, tokenID)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (s *MemoryRevocationStore) RevokeUserTokens(_ context.Context, userID uint, issuedBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.userCutoffs[userID] = userCutoff{
+		issuedBefore: issuedBefore,
+		expiresAt:    time.Now().Add(AccessTokenTTL()),
+	}
+	return nil
+}
+
+func (s *MemoryRevocationStore) UserTokensRevokedBefore(_ context.Context, userID uint) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff, ok := s.userCutoffs[userID]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	if time.Now().After(cutoff.expiresAt) {
+		delete(s.userCutoffs, userID)
+		return time.Time{}, nil
+	}
+
+	return cutoff.issuedBefore, nil
+}
@@ -0,0 +1,95 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryRevocationStoreRevoke(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	if err := store.Revoke(ctx, "live", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Revoke(ctx, "expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		"live":    true,
+		"expired": false,
+		"unknown": false,
+	}
+	for tokenID, want := range tests {
+		revoked, err := store.IsRevoked(ctx, tokenID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revoked != want {
+			t.Errorf("IsRevoked(%q) = %v, want %v", tokenID, revoked, want)
+		}
+	}
+}
+
+func TestMemoryRevocationStoreDropsEntriesOnceExpired(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	// An entry outlives its token only until the token would have expired
+	store.entries["token"] = time.Now().Add(-time.Second)
+
+	revoked, err := store.IsRevoked(ctx, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked {
+		t.Error("IsRevoked() = true for an entry past its expiry")
+	}
+	if _, ok := store.entries["token"]; ok {
+		t.Error("expired entry was not removed")
+	}
+}
+
+func TestMemoryRevocationStoreRevokeUserTokens(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	ctx := context.Background()
+
+	cutoff, err := store.UserTokensRevokedBefore(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cutoff.IsZero() {
+		t.Errorf("UserTokensRevokedBefore() = %v before any revocation, want zero", cutoff)
+	}
+
+	issuedBefore := time.Now().Truncate(time.Second)
+	if err := store.RevokeUserTokens(ctx, 7, issuedBefore); err != nil {
+		t.Fatal(err)
+	}
+
+	cutoff, err = store.UserTokensRevokedBefore(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cutoff.Equal(issuedBefore) {
+		t.Errorf("UserTokensRevokedBefore() = %v, want %v", cutoff, issuedBefore)
+	}
+
+	if other, _ := store.UserTokensRevokedBefore(ctx, 8); !other.IsZero() {
+		t.Errorf("UserTokensRevokedBefore(8) = %v, want zero", other)
+	}
+
+	// Once every token issued before the cutoff has expired the cutoff is
+	// forgotten
+	store.userCutoffs[7] = userCutoff{issuedBefore: issuedBefore, expiresAt: time.Now().Add(-time.Second)}
+
+	cutoff, err = store.UserTokensRevokedBefore(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cutoff.IsZero() {
+		t.Errorf("UserTokensRevokedBefore() = %v after expiry, want zero", cutoff)
+	}
+}
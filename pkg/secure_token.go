@@ -0,0 +1,27 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateSecureToken returns a random URL-safe token together with the
+// SHA-256 hash that should be stored in its place.
+func GenerateSecureToken() (string, string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(bytes)
+	return token, HashToken(token), nil
+}
+
+// HashToken hashes a high-entropy token for storage and lookup. Tokens are
+// random, so a fast hash is enough; passwords must use HashPassword instead.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
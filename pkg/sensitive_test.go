@@ -0,0 +1,278 @@
+package pkg_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
+	"github.com/radenadri/go-boilerplate/internal/domain/models"
+)
+
+type secretHolder struct {
+	Secret string `json:"secret" sensitive:"true"`
+}
+
+type hiddenSecretHolder struct {
+	Secret string `json:"-" sensitive:"true"`
+}
+
+// leakingSecretHolder hides the field from encoding/json but serializes it
+// anyway through MarshalJSON.
+type leakingSecretHolder struct {
+	Secret string `json:"-" sensitive:"true"`
+}
+
+func (h leakingSecretHolder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"secret": h.Secret})
+}
+
+type recursiveHolder struct {
+	Secret   string             `json:"-" sensitive:"true"`
+	Children []recursiveHolder  `json:"children"`
+	Parent   *recursiveHolder   `json:"parent"`
+	Leak     []*secretHolder    `json:"leak"`
+	ByName   map[string]*string `json:"by_name"`
+}
+
+func TestEnsureNoSensitiveFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr bool
+	}{
+		{name: "direct field", value: secretHolder{}, wantErr: true},
+		{name: "hidden field", value: hiddenSecretHolder{}},
+		{name: "custom marshaler", value: leakingSecretHolder{}, wantErr: true},
+		{name: "nested struct", value: struct{ Inner secretHolder }{}, wantErr: true},
+		{name: "pointer", value: struct{ Inner *secretHolder }{}, wantErr: true},
+		{name: "slice", value: struct{ Items []secretHolder }{}, wantErr: true},
+		{name: "slice of pointers", value: struct{ Items []*secretHolder }{}, wantErr: true},
+		{name: "array", value: struct{ Items [2]secretHolder }{}, wantErr: true},
+		{name: "map", value: struct{ Items map[string]secretHolder }{}, wantErr: true},
+		{name: "hidden in containers", value: struct {
+			Items  []hiddenSecretHolder
+			ByName map[string]*hiddenSecretHolder
+		}{}},
+		{name: "recursive type", value: recursiveHolder{}, wantErr: true},
+		{name: "pointer to value", value: &secretHolder{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ensureNoSensitiveFields(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ensureNoSensitiveFields() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// serializedTypes has a sample of every type declared in the models and
+// response packages. TestSerializedTypesAreListed fails when a new type is
+// missing here, so it cannot skip the sensitive field check.
+var serializedTypes = map[string]interface{}{
+	"models.APIKey":             models.APIKey{},
+	"models.ClientInfo":         models.ClientInfo{},
+	"models.Identity":           models.Identity{},
+	"models.LinkedAccount":      models.LinkedAccount{},
+	"models.PasswordResetToken": models.PasswordResetToken{},
+	"models.Permission":         models.Permission{},
+	"models.RecoveryCode":       models.RecoveryCode{},
+	"models.RefreshToken":       models.RefreshToken{},
+	"models.Role":               models.Role{},
+	"models.Session":            models.Session{},
+	"models.User":               models.User{},
+
+	"response.APIKeyCreatedResponse":       response.APIKeyCreatedResponse{},
+	"response.APIKeyResponse":              response.APIKeyResponse{},
+	"response.Links":                       response.Links{},
+	"response.OAuthAuthorizationResponse":  response.OAuthAuthorizationResponse{},
+	"response.PaginatedResponse":           response.PaginatedResponse[models.User]{},
+	"response.Pagination":                  response.Pagination{},
+	"response.Problem":                     response.Problem{},
+	"response.RecoveryCodesResponse":       response.RecoveryCodesResponse{},
+	"response.Response":                    response.Response{Data: models.User{}},
+	"response.SessionResponse":             response.SessionResponse{},
+	"response.TwoFactorEnrollmentResponse": response.TwoFactorEnrollmentResponse{},
+	"response.UserLoginResponse":           response.UserLoginResponse{},
+	"response.UserResponse":                response.UserResponse{},
+	"response.ValidationError":             response.ValidationError{},
+}
+
+func TestSerializedTypesAreListed(t *testing.T) {
+	declared := append(
+		declaredTypes(t, "models", "../internal/domain/models"),
+		declaredTypes(t, "response", "../internal/delivery/dto/response")...,
+	)
+
+	for _, name := range declared {
+		if _, ok := serializedTypes[name]; !ok {
+			t.Errorf("%s is not listed in serializedTypes", name)
+		}
+	}
+}
+
+func TestSerializedTypesHaveNoSensitiveFields(t *testing.T) {
+	names := make([]string, 0, len(serializedTypes))
+	for name := range serializedTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := serializedTypes[name]
+		if err := ensureNoSensitiveFields(value); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+
+		// Response.Data is an interface, so its sample is checked on its own
+		if data := reflect.ValueOf(value).FieldByName("Data"); data.IsValid() && data.Kind() == reflect.Interface && !data.IsNil() {
+			if err := ensureNoSensitiveFields(data.Interface()); err != nil {
+				t.Errorf("%s data: %v", name, err)
+			}
+		}
+	}
+}
+
+// declaredTypes lists the exported struct types declared in the package
+// directory, qualified with the package name.
+func declaredTypes(t *testing.T, packageName string, dir string) []string {
+	t.Helper()
+
+	packages, err := parser.ParseDir(token.NewFileSet(), dir, nil, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, file := range packages[packageName].Files {
+		for _, declaration := range file.Decls {
+			genDecl, ok := declaration.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if _, isStruct := typeSpec.Type.(*ast.StructType); isStruct && typeSpec.Name.IsExported() {
+					names = append(names, packageName+"."+typeSpec.Name.Name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+const sensitiveMarker = "__sensitive_value__"
+
+// ensureNoSensitiveFields marshals each value with every string field tagged
+// `sensitive:"true"` set to a marker, and fails if the marker shows up in the
+// JSON. Fields are found through nested structs, pointers, slices, arrays and
+// maps, so a type that starts leaking a secret, through a json tag or a custom
+// MarshalJSON, fails the suite.
+func ensureNoSensitiveFields(values ...interface{}) error {
+	for _, value := range values {
+		valueType := reflect.TypeOf(value)
+		if valueType.Kind() == reflect.Ptr {
+			valueType = valueType.Elem()
+		}
+
+		sample := reflect.New(valueType).Elem()
+		if !markSensitiveFields(sample, make(map[reflect.Type]bool)) {
+			continue
+		}
+
+		body, err := json.Marshal(sample.Interface())
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", valueType, err)
+		}
+
+		if strings.Contains(string(body), sensitiveMarker) {
+			return fmt.Errorf("%s exposes a sensitive field in its JSON output", valueType)
+		}
+	}
+
+	return nil
+}
+
+// markSensitiveFields fills the sensitive string fields reachable from value
+// and reports whether any were found. Containers only get an element when it
+// holds a sensitive field, and visiting stops recursive types from being
+// expanded forever.
+func markSensitiveFields(value reflect.Value, visiting map[reflect.Type]bool) bool {
+	switch value.Kind() {
+	case reflect.Ptr:
+		element := reflect.New(value.Type().Elem())
+		if !markSensitiveFields(element.Elem(), visiting) {
+			return false
+		}
+		value.Set(element)
+		return true
+	case reflect.Slice:
+		element := reflect.New(value.Type().Elem()).Elem()
+		if !markSensitiveFields(element, visiting) {
+			return false
+		}
+		value.Set(reflect.Append(reflect.MakeSlice(value.Type(), 0, 1), element))
+		return true
+	case reflect.Array:
+		found := false
+		for i := 0; i < value.Len(); i++ {
+			if markSensitiveFields(value.Index(i), visiting) {
+				found = true
+			}
+		}
+		return found
+	case reflect.Map:
+		element := reflect.New(value.Type().Elem()).Elem()
+		if !markSensitiveFields(element, visiting) {
+			return false
+		}
+		entries := reflect.MakeMap(value.Type())
+		entries.SetMapIndex(reflect.Zero(value.Type().Key()), element)
+		value.Set(entries)
+		return true
+	case reflect.Struct:
+		return markSensitiveStructFields(value, visiting)
+	default:
+		return false
+	}
+}
+
+func markSensitiveStructFields(value reflect.Value, visiting map[reflect.Type]bool) bool {
+	if visiting[value.Type()] {
+		return false
+	}
+	visiting[value.Type()] = true
+	defer delete(visiting, value.Type())
+
+	found := false
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+
+		if !structField.IsExported() {
+			continue
+		}
+
+		if structField.Tag.Get("sensitive") == "true" && field.Kind() == reflect.String {
+			field.SetString(sensitiveMarker)
+			found = true
+			continue
+		}
+
+		if markSensitiveFields(field, visiting) {
+			found = true
+		}
+	}
+
+	return found
+}
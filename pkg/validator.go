@@ -1,6 +1,10 @@
 package pkg
 
 import (
+	"errors"
+	"fmt"
+	"unicode"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/radenadri/go-boilerplate/internal/delivery/dto/response"
 )
@@ -14,14 +18,45 @@ func InitValidator() {
 		password := fl.Field().String()
 		return len(password) >= 8 && len(password) <= 32
 	})
+
+	Validator.RegisterValidation("strong_password", func(fl validator.FieldLevel) bool {
+		return isStrongPassword(fl.Field().String())
+	})
+}
+
+// isStrongPassword requires at least one lowercase letter, one uppercase
+// letter, one digit and one symbol.
+func isStrongPassword(password string) bool {
+	var hasLower, hasUpper, hasDigit, hasSymbol bool
+
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	return hasLower && hasUpper && hasDigit && hasSymbol
 }
 
 func GetValidatorErrorMessage(err validator.FieldError) string {
 	switch err.Tag() {
-	case "required":
+	case "required", "required_without":
 		return "This field is required"
+	case "excludes":
+		return "Should not contain " + err.Param()
 	case "password":
 		return "Password must be at least 8 characters long"
+	case "strong_password":
+		return "Password must contain an uppercase letter, a lowercase letter, a digit and a symbol"
+	case "eqfield":
+		return "Should match " + err.Param()
 	case "email":
 		return "Invalid email format"
 	case "min":
@@ -30,22 +65,42 @@ func GetValidatorErrorMessage(err validator.FieldError) string {
 		return "Should not be longer than " + err.Param() + " characters"
 	case "gte":
 		return "Should be greater than or equal to " + err.Param()
+	case "lte":
+		return "Should be less than or equal to " + err.Param()
+	case "datetime":
+		return "Should be a date in the format " + err.Param()
 	default:
 		return "Invalid value"
 	}
 }
 
 func FormatValidationErrors(err error) []response.ValidationError {
-	var errors []response.ValidationError
+	var validationErrors []response.ValidationError
 
 	for _, err := range err.(validator.ValidationErrors) {
 		var element response.ValidationError
 		element.Field = err.Field()
 		element.Rule = err.Tag()
-		element.Value = err.Value().(string)
+		element.Value = fmt.Sprint(err.Value())
 		element.Reason = GetValidatorErrorMessage(err)
-		errors = append(errors, element)
+		validationErrors = append(validationErrors, element)
+	}
+
+	return validationErrors
+}
+
+// ValidateStruct runs the validator and converts failures into a validation
+// AppError carrying one entry per invalid field.
+func ValidateStruct(payload interface{}) error {
+	err := Validator.Struct(payload)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
 	}
 
-	return errors
+	return NewValidationError("validation_failed", "Validation failed", FormatValidationErrors(validationErrors)...)
 }
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"time"
+)
+
+// ParseDurationOrDefault parses a Go duration string such as "15m" and falls
+// back to defaultValue when it is empty, invalid or not positive.
+func ParseDurationOrDefault(value string, defaultValue time.Duration) time.Duration {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return defaultValue
+	}
+
+	return duration
+}
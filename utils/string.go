@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"time"
 )
 
@@ -18,3 +19,9 @@ func JoinStrings(strings []string) string {
 	}
 	return result
 }
+
+// NormalizeEmail trims and lowercases an email address so lookups and
+// uniqueness checks do not depend on how the user typed it.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}